@@ -1,78 +1,142 @@
-package main
-
-import (
-	"context"
-	"flag"
-	"log"
-	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
-
-	"github.com/sazap10/bugsnag-mcp/pkg/config"
-	"github.com/sazap10/bugsnag-mcp/pkg/server"
-)
-
-const (
-	name    = "bugsnag-mcp"
-	version = "0.0.1"
-)
-
-func main() {
-	cfg, err := config.NewConfig()
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
-	}
-
-	// Parse command line flags
-	transportType := flag.String("transport", "stdio", "Transport type (stdio or sse)")
-	sseAddr := flag.String("sse-address", "localhost:8080", "Address for SSE transport")
-	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	flag.Parse()
-
-	// Set up logging
-	level := parseLogLevel(*logLevel)
-	consoleHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
-	logger := slog.New(consoleHandler)
-	slog.SetDefault(logger)
-
-	// Create MCP server
-	mcpServer := server.NewMCPServer(name, version, cfg)
-
-	// signal handling
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	go func() {
-		// Handle signals
-		signalChan := make(chan os.Signal, 1)
-		signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
-		<-signalChan
-		slog.Info("Received shutdown signal, shutting down...")
-		cancel()
-	}()
-
-	// Start the server
-	switch *transportType {
-	case "stdio":
-		slog.Info("Starting bugsnag-mcp with stdio transport")
-		if err := server.ServeStdio(ctx, mcpServer); err != nil {
-			log.Fatalf("failed to start server: %v", err)
-		}
-	case "sse":
-		slog.Info("Starting bugsnag-mcp with SSE transport", slog.String("address", *sseAddr))
-		if err := server.ServeSSE(ctx, mcpServer, *sseAddr); err != nil {
-			log.Fatalf("failed to start server: %v", err)
-		}
-	default:
-		log.Fatalf("unknown transport type: %s", *transportType)
-	}
-}
-
-func parseLogLevel(level string) slog.Level {
-	var l slog.Level
-	if err := l.UnmarshalText([]byte(level)); err != nil {
-		return slog.LevelInfo
-	}
-	return l
-}
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/auth"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+	"github.com/sazap10/bugsnag-mcp/pkg/server"
+)
+
+const (
+	name    = "bugsnag-mcp"
+	version = "0.0.1"
+)
+
+func main() {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// Parse command line flags
+	transportType := flag.String("transport", "stdio", "Transport type (stdio, sse or http)")
+	sseAddr := flag.String("sse-address", "localhost:8080", "Address for SSE transport")
+	httpAddr := flag.String("http-address", "localhost:8080", "Address for streamable HTTP transport")
+	httpEndpoint := flag.String("http-endpoint", "/mcp", "Endpoint path for streamable HTTP transport")
+	httpStateless := flag.Bool("http-stateless", false, "Disable session tracking for streamable HTTP transport")
+	httpHeartbeat := flag.Duration("http-heartbeat", 30*time.Second, "Heartbeat interval for streamable HTTP transport (0 to disable)")
+	drainTimeout := flag.Duration("drain-timeout", 30*time.Second, "Time in-flight tool calls are given to finish on shutdown (sse and http transports)")
+	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	fake := flag.Bool("fake", false, "Serve built-in demo data from a local fake Bugsnag API instead of calling Bugsnag, no token needed")
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Set up logging, on stderr as stdout carries the JSON-RPC messages of the stdio transport
+	level := parseLogLevel(*logLevel)
+	consoleHandler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	logger := slog.New(consoleHandler)
+	slog.SetDefault(logger)
+
+	// Point the clients at a fake Bugsnag API for demos
+	if *fake {
+		fixtures := bugsnagtest.DefaultFixtures()
+		fixtures.Rebase(time.Now().Truncate(time.Second))
+		fakeAPI := bugsnagtest.NewServer(fixtures)
+		defer fakeAPI.Close()
+		cfg.UseEndpoint(fakeAPI.URL, bugsnagtest.Token)
+		slog.Warn("Serving demo data from a fake Bugsnag API", slog.String("url", fakeAPI.URL))
+	}
+
+	// Set up authentication of the network transports
+	authenticator, err := auth.New(auth.Options{
+		Tokens:   cfg.AuthTokens,
+		JWKSFile: cfg.AuthJWKSFile,
+		Issuer:   cfg.AuthIssuer,
+		Audience: cfg.AuthAudience,
+	})
+	if err != nil {
+		log.Fatalf("failed to set up authentication: %v", err)
+	}
+	if authenticator == nil && *transportType != "stdio" && cfg.AuthToken != "" {
+		slog.Warn("No MCP_AUTH_TOKENS or MCP_AUTH_JWKS_FILE configured, anyone who can reach the server can use its Bugsnag token")
+	}
+	if cfg.AuthToken == "" {
+		if *transportType == "stdio" {
+			log.Fatalf("BUGSNAG_AUTH_TOKEN is required with the stdio transport")
+		}
+		slog.Info("No BUGSNAG_AUTH_TOKEN configured, clients must send their own in the " + server.BugsnagTokenHeader + " header")
+	}
+
+	// Create MCP server
+	mcpServer := server.NewMCPServer(name, version, cfg)
+
+	// signal handling
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		// Handle signals
+		signalChan := make(chan os.Signal, 1)
+		signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+		<-signalChan
+		slog.Info("Received shutdown signal, shutting down...")
+		cancel()
+		// A second signal skips draining
+		<-signalChan
+		slog.Warn("Received second shutdown signal, exiting immediately")
+		os.Exit(1)
+	}()
+
+	// Start the server
+	switch *transportType {
+	case "stdio":
+		slog.Info("Starting bugsnag-mcp with stdio transport")
+		if err := server.ServeStdio(ctx, mcpServer); err != nil {
+			log.Fatalf("server error: %v", err)
+		}
+	case "sse":
+		slog.Info("Starting bugsnag-mcp with SSE transport", slog.String("address", *sseAddr))
+		opts := server.SSEOptions{
+			DrainTimeout: *drainTimeout,
+			Auth:         authenticator,
+		}
+		if err := server.ServeSSE(ctx, mcpServer, *sseAddr, opts); err != nil {
+			log.Fatalf("server error: %v", err)
+		}
+	case "http":
+		slog.Info("Starting bugsnag-mcp with streamable HTTP transport", slog.String("address", *httpAddr))
+		opts := server.HTTPOptions{
+			EndpointPath:      *httpEndpoint,
+			Stateless:         *httpStateless,
+			HeartbeatInterval: *httpHeartbeat,
+			DrainTimeout:      *drainTimeout,
+			Auth:              authenticator,
+		}
+		if err := server.ServeHTTP(ctx, mcpServer, *httpAddr, opts); err != nil {
+			log.Fatalf("server error: %v", err)
+		}
+	default:
+		log.Fatalf("unknown transport type: %s", *transportType)
+	}
+	if err := cfg.Close(); err != nil {
+		slog.Error("Failed to save the response cache", slog.String("error", err.Error()))
+	}
+	slog.Info("Server stopped")
+}
+
+func parseLogLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
@@ -0,0 +1,140 @@
+// Package auth authenticates MCP clients connecting over the network transports.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader is the header an API key can be sent in, as an alternative to a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// Method is how a client was authenticated.
+type Method string
+
+const (
+	// MethodToken means the client presented one of the configured static tokens.
+	MethodToken Method = "token"
+	// MethodJWT means the client presented a JWT signed by a key of the configured JWKS.
+	MethodJWT Method = "jwt"
+)
+
+// Identity describes an authenticated client.
+type Identity struct {
+	Method Method
+	// Subject is the sub claim of a JWT. It is empty for static tokens.
+	Subject string
+}
+
+// Options configures an Authenticator.
+type Options struct {
+	// Tokens are the static bearer tokens / API keys accepted from clients.
+	Tokens []string
+	// JWKSFile is the path to a JSON Web Key Set used to validate JWT bearer tokens.
+	JWKSFile string
+	// Issuer is the required iss claim of JWTs. It must be set along with JWKSFile.
+	Issuer string
+	// Audience is the required aud claim of JWTs, identifying this server. It must be set along with
+	// JWKSFile, or tokens the identity provider issued for any other service would be accepted.
+	Audience string
+}
+
+// Authenticator checks the credentials of HTTP requests.
+type Authenticator struct {
+	// tokenHashes are the SHA-256 hashes of the static tokens, compared in constant time.
+	tokenHashes [][sha256.Size]byte
+	jwt         *jwtValidator
+}
+
+// New creates an Authenticator from the options. It returns nil when no credentials are configured,
+// in which case Middleware lets every request through.
+func New(opts Options) (*Authenticator, error) {
+	a := &Authenticator{}
+	for _, token := range opts.Tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			a.tokenHashes = append(a.tokenHashes, sha256.Sum256([]byte(token)))
+		}
+	}
+	if opts.JWKSFile != "" {
+		issuer, audience := strings.TrimSpace(opts.Issuer), strings.TrimSpace(opts.Audience)
+		if issuer == "" || audience == "" {
+			return nil, errors.New("JWT validation requires an issuer and an audience (MCP_AUTH_ISSUER and MCP_AUTH_AUDIENCE), " +
+				"so tokens issued for other services are rejected")
+		}
+		v, err := newJWTValidator(opts.JWKSFile, issuer, audience)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = v
+	}
+	if len(a.tokenHashes) == 0 && a.jwt == nil {
+		return nil, nil
+	}
+	return a, nil
+}
+
+// Authenticate returns the identity of the client sending r, or an error if it did not present
+// valid credentials.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	credential := r.Header.Get(APIKeyHeader)
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		credential = strings.TrimSpace(token)
+	}
+	if credential == "" {
+		return nil, errors.New("missing credentials")
+	}
+
+	hash := sha256.Sum256([]byte(credential))
+	matched := 0
+	for _, h := range a.tokenHashes {
+		matched |= subtle.ConstantTimeCompare(hash[:], h[:])
+	}
+	if matched == 1 {
+		return &Identity{Method: MethodToken}, nil
+	}
+
+	if a.jwt != nil && strings.Count(credential, ".") == 2 {
+		claims, err := a.jwt.validate(credential)
+		if err != nil {
+			return nil, err
+		}
+		return &Identity{Method: MethodJWT, Subject: claims.Subject}, nil
+	}
+	return nil, errors.New("invalid credentials")
+}
+
+// Middleware rejects requests without valid credentials with 401 Unauthorized, and adds the identity
+// of the client to the context of the others. A nil Authenticator lets every request through.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := a.Authenticate(r)
+		if err != nil {
+			slog.Debug("Rejected unauthenticated request", slog.String("path", r.URL.Path), slog.String("reason", err.Error()))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bugsnag-mcp", error="invalid_token"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the identity of the client.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the client, or nil if the request was not authenticated.
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "bugsnag-mcp"
+)
+
+// stubIssuer mints JWTs with its own keys and publishes the public keys as a JWKS file.
+type stubIssuer struct {
+	t        *testing.T
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	ec384Key *ecdsa.PrivateKey
+	jwksFile string
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	ec384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "alg": "ES256", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "EC", "kid": "ec-384", "crv": "P-384", "x": b64(ec384Key.X.FillBytes(make([]byte, 48))), "y": b64(ec384Key.Y.FillBytes(make([]byte, 48)))},
+	}}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+	return &stubIssuer{t: t, rsaKey: rsaKey, ecKey: ecKey, ec384Key: ec384Key, jwksFile: path}
+}
+
+// claims returns valid claims for the test issuer and audience, with overrides applied.
+func (s *stubIssuer) claims(overrides map[string]any) map[string]any {
+	claims := map[string]any{
+		"sub": "user-1",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	return claims
+}
+
+// sign mints a JWT with the given algorithm (RS256, ES256 or none) and key ID.
+func (s *stubIssuer) sign(alg, kid string, claims map[string]any) string {
+	s.t.Helper()
+	return s.signAs(alg, alg, kid, claims)
+}
+
+// signAs mints a JWT whose header claims the algorithm headerAlg, signed with the algorithm alg. HS256
+// tokens are signed with the DER encoded RSA public key as the HMAC secret, as in algorithm confusion attacks,
+// and ES256-P384 tokens with SHA-256 and the P-384 key, as in curve confusion attacks.
+func (s *stubIssuer) signAs(headerAlg, alg, kid string, claims map[string]any) string {
+	s.t.Helper()
+	header := map[string]string{"alg": headerAlg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	headerJSON, _ := json.Marshal(header)
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, s.rsaKey, crypto.SHA256, digest.Sum(nil))
+		if err != nil {
+			s.t.Fatalf("rsa.SignPKCS1v15() error = %v", err)
+		}
+		signature = sig
+	case "ES256":
+		r, sv, err := ecdsa.Sign(rand.Reader, s.ecKey, digest.Sum(nil))
+		if err != nil {
+			s.t.Fatalf("ecdsa.Sign() error = %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), sv.FillBytes(make([]byte, 32))...)
+	case "ES256-P384":
+		r, sv, err := ecdsa.Sign(rand.Reader, s.ec384Key, digest.Sum(nil))
+		if err != nil {
+			s.t.Fatalf("ecdsa.Sign() error = %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 48)), sv.FillBytes(make([]byte, 48))...)
+	case "HS256":
+		secret, err := x509.MarshalPKIXPublicKey(&s.rsaKey.PublicKey)
+		if err != nil {
+			s.t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestNew(t *testing.T) {
+	a, err := New(Options{Tokens: []string{" ", ""}})
+	if err != nil || a != nil {
+		t.Errorf("New() without credentials = %v, %v, want nil, nil", a, err)
+	}
+
+	if _, err := New(Options{JWKSFile: filepath.Join(t.TempDir(), "missing.json"), Issuer: testIssuer, Audience: testAudience}); err == nil {
+		t.Error("New() with a missing JWKS file error = nil, want an error")
+	}
+
+	issuer := newStubIssuer(t)
+	for _, opts := range []Options{
+		{JWKSFile: issuer.jwksFile},
+		{JWKSFile: issuer.jwksFile, Issuer: testIssuer},
+		{JWKSFile: issuer.jwksFile, Audience: testAudience},
+		{JWKSFile: issuer.jwksFile, Issuer: testIssuer, Audience: " "},
+	} {
+		if _, err := New(opts); err == nil || !strings.Contains(err.Error(), "requires an issuer and an audience") {
+			t.Errorf("New(%+v) error = %v, want the issuer and audience required", opts, err)
+		}
+	}
+
+	// A key declaring an ECDSA algorithm must be on the curve of that algorithm
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	data, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ec-1", "alg": "ES256", "crv": "P-384", "x": b64(key.X.FillBytes(make([]byte, 48))), "y": b64(key.Y.FillBytes(make([]byte, 48)))},
+	}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+	if _, err := New(Options{JWKSFile: path, Issuer: testIssuer, Audience: testAudience}); err == nil || !strings.Contains(err.Error(), "cannot be used with curve") {
+		t.Errorf("New() with an ES256 key on P-384 error = %v, want the key rejected", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	issuer := newStubIssuer(t)
+	otherIssuer := newStubIssuer(t)
+	a, err := New(Options{
+		Tokens:   []string{"secret-1", "secret-2"},
+		JWKSFile: issuer.jwksFile,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		header      string
+		value       string
+		wantMethod  Method
+		wantSubject string
+		wantErr     string
+	}{
+		{name: "bearer token", header: "Authorization", value: "Bearer secret-2", wantMethod: MethodToken},
+		{name: "lower case scheme", header: "Authorization", value: "bearer secret-1", wantMethod: MethodToken},
+		{name: "API key header", header: APIKeyHeader, value: "secret-1", wantMethod: MethodToken},
+		{name: "missing credentials", wantErr: "missing credentials"},
+		{name: "unknown token", header: "Authorization", value: "Bearer secret-3", wantErr: "invalid credentials"},
+		{name: "basic auth", header: "Authorization", value: "Basic c2VjcmV0LTE=", wantErr: "missing credentials"},
+		{name: "RS256 JWT", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(nil)), wantMethod: MethodJWT, wantSubject: "user-1"},
+		{name: "ES256 JWT", header: "Authorization", value: "Bearer " + issuer.sign("ES256", "ec-1", issuer.claims(nil)), wantMethod: MethodJWT, wantSubject: "user-1"},
+		{name: "JWT with audience list", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "", issuer.claims(map[string]any{"aud": []string{"other", testAudience}})), wantMethod: MethodJWT, wantSubject: "user-1"},
+		{name: "JWT signed by another issuer", header: "Authorization", value: "Bearer " + otherIssuer.sign("RS256", "rsa-1", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "JWT with unknown key ID", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-2", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "unsigned JWT", header: "Authorization", value: "Bearer " + issuer.sign("none", "", issuer.claims(nil)), wantErr: "unsupported signing algorithm"},
+		{name: "expired JWT", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()})), wantErr: "expired"},
+		{name: "JWT without expiry", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"exp": nil})), wantErr: "no expiry"},
+		{name: "JWT not valid yet", header: "Authorization", value: "Bearer " + issuer.sign("ES256", "ec-1", issuer.claims(map[string]any{"nbf": time.Now().Add(time.Hour).Unix()})), wantErr: "not valid yet"},
+		{name: "JWT from another issuer", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"iss": "https://evil.example.com"})), wantErr: "unexpected token issuer"},
+		{name: "JWT without key ID", header: "Authorization", value: "Bearer " + issuer.sign("ES256", "", issuer.claims(nil)), wantMethod: MethodJWT, wantSubject: "user-1"},
+		{name: "JWT without key ID signed by another issuer", header: "Authorization", value: "Bearer " + otherIssuer.sign("ES256", "", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "HS256 JWT keyed with the RSA public key", header: "Authorization", value: "Bearer " + issuer.sign("HS256", "rsa-1", issuer.claims(nil)), wantErr: "unsupported signing algorithm"},
+		{name: "unsigned JWT with a key ID", header: "Authorization", value: "Bearer " + issuer.sign("none", "rsa-1", issuer.claims(nil)), wantErr: "unsupported signing algorithm"},
+		{name: "lower case algorithm", header: "Authorization", value: "Bearer " + issuer.signAs("rs256", "RS256", "rsa-1", issuer.claims(nil)), wantErr: "unsupported signing algorithm"},
+		{name: "RS256 header over an ES256 signature", header: "Authorization", value: "Bearer " + issuer.signAs("RS256", "ES256", "ec-1", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "ES256 header over an RS256 signature", header: "Authorization", value: "Bearer " + issuer.signAs("ES256", "RS256", "", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "ES256 JWT signed with a P-384 key", header: "Authorization", value: "Bearer " + issuer.signAs("ES256", "ES256-P384", "ec-384", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "ES256 JWT signed with a P-384 key without key ID", header: "Authorization", value: "Bearer " + issuer.signAs("ES256", "ES256-P384", "", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "RS256 JWT naming the EC key", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "ec-1", issuer.claims(nil)), wantErr: "invalid token signature"},
+		{name: "JWT expired within the clock skew", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"exp": time.Now().Add(-clockSkew / 2).Unix()})), wantMethod: MethodJWT, wantSubject: "user-1"},
+		{name: "JWT expired beyond the clock skew", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"exp": time.Now().Add(-2 * clockSkew).Unix()})), wantErr: "expired"},
+		{name: "JWT valid within the clock skew", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"nbf": time.Now().Add(clockSkew / 2).Unix()})), wantMethod: MethodJWT, wantSubject: "user-1"},
+		{name: "JWT valid beyond the clock skew", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"nbf": time.Now().Add(2 * clockSkew).Unix()})), wantErr: "not valid yet"},
+		{name: "JWT without issuer", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"iss": nil})), wantErr: "unexpected token issuer"},
+		{name: "JWT without audience", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"aud": nil})), wantErr: "not intended for this server"},
+		{name: "JWT for another audience", header: "Authorization", value: "Bearer " + issuer.sign("RS256", "rsa-1", issuer.claims(map[string]any{"aud": "other"})), wantErr: "not intended for this server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			identity, err := a.Authenticate(r)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if identity.Method != tt.wantMethod || identity.Subject != tt.wantSubject {
+				t.Errorf("Authenticate() = %+v, want method %q and subject %q", identity, tt.wantMethod, tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	issuer := newStubIssuer(t)
+	a, err := New(Options{JWKSFile: issuer.jwksFile, Issuer: testIssuer, Audience: testAudience})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var gotIdentity *Identity
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIdentity = IdentityFromContext(r.Context())
+	}))
+
+	t.Run("rejects unauthenticated requests", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+		}
+		if got := rec.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer ") {
+			t.Errorf("WWW-Authenticate = %q, want a Bearer challenge", got)
+		}
+	})
+
+	t.Run("passes the identity on", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/sse", nil)
+		r.Header.Set("Authorization", "Bearer "+issuer.sign("ES256", "ec-1", issuer.claims(map[string]any{"sub": "user-2"})))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if gotIdentity == nil || gotIdentity.Subject != "user-2" {
+			t.Errorf("identity = %+v, want subject user-2", gotIdentity)
+		}
+	})
+
+	t.Run("nil authenticator accepts every request", func(t *testing.T) {
+		var none *Authenticator
+		rec := httptest.NewRecorder()
+		none.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+}
@@ -0,0 +1,329 @@
+package bugsnag
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EntityType is the type of entity a Bugsnag API path returns, which decides how long its responses are cached.
+type EntityType string
+
+const (
+	EntityOrganizations EntityType = "organizations"
+	EntityProjects      EntityType = "projects"
+	EntityErrors        EntityType = "errors"
+	EntityEvents        EntityType = "events"
+	EntityReleases      EntityType = "releases"
+	EntityTrends        EntityType = "trends"
+	EntityPivots        EntityType = "pivots"
+	EntitySavedSearches EntityType = "saved_searches"
+)
+
+// DefaultCacheTTLs are how long the responses of each entity type are cached by default. Events never change,
+// so they are kept the longest, while errors and their trends and pivots change as new events come in.
+var DefaultCacheTTLs = map[EntityType]time.Duration{
+	EntityOrganizations: time.Hour,
+	EntityProjects:      10 * time.Minute,
+	EntityErrors:        time.Minute,
+	EntityEvents:        time.Hour,
+	EntityReleases:      5 * time.Minute,
+	EntityTrends:        time.Minute,
+	EntityPivots:        time.Minute,
+	EntitySavedSearches: 10 * time.Minute,
+}
+
+// entitySegments maps the path segments of the Bugsnag API to the entity type they return.
+var entitySegments = map[string]EntityType{
+	"organizations":  EntityOrganizations,
+	"projects":       EntityProjects,
+	"errors":         EntityErrors,
+	"events":         EntityEvents,
+	"releases":       EntityReleases,
+	"trend":          EntityTrends,
+	"trend_buckets":  EntityTrends,
+	"pivots":         EntityPivots,
+	"pivot_values":   EntityPivots,
+	"saved_searches": EntitySavedSearches,
+}
+
+// PathEntityType returns the type of entity returned by a Bugsnag API path, e.g. events for
+// projects/{id}/errors/{id}/events, or false if it is unknown.
+func PathEntityType(path string) (EntityType, bool) {
+	segments := pathSegments(&url.URL{Path: path})
+	for i := len(segments) - 1; i >= 0; i-- {
+		if entity, ok := entitySegments[segments[i]]; ok {
+			return entity, true
+		}
+	}
+	return "", false
+}
+
+// CacheOptions configures a Cache.
+type CacheOptions struct {
+	// TTLs are how long the responses of each entity type are cached. Entity types without a positive TTL are not cached.
+	TTLs map[EntityType]time.Duration
+	// MaxEntries is the number of responses kept, the least recently used being evicted first.
+	MaxEntries int
+	// Path is the file the cache is loaded from and saved to. Empty keeps the cache in memory only.
+	Path string
+}
+
+// Cache caches the successful responses to GET requests of the Bugsnag API. It is shared by the clients
+// of all the auth tokens, whose responses are cached separately.
+type Cache struct {
+	ttls       map[EntityType]time.Duration
+	maxEntries int
+	path       string
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a cached response, persisted as JSON.
+type cacheEntry struct {
+	Key     string      `json:"key"`
+	Path    string      `json:"path"`
+	Expires time.Time   `json:"expires"`
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+}
+
+// NewCache creates a cache, loading the unexpired entries from opts.Path if it exists.
+func NewCache(opts CacheOptions) (*Cache, error) {
+	c := &Cache{
+		ttls:       opts.TTLs,
+		maxEntries: opts.MaxEntries,
+		path:       opts.Path,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+	}
+	if c.path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	var entries []*cacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		// A corrupt cache is not worth failing over, it is rebuilt as responses come in
+		slog.Warn("Ignoring invalid cache file", slog.String("path", c.path), slog.String("error", err.Error()))
+		return c, nil
+	}
+	now := c.now()
+	for _, e := range entries {
+		if now.Before(e.Expires) {
+			c.add(e)
+		}
+	}
+	return c, nil
+}
+
+// Save writes the unexpired entries to the cache file, if the cache has one.
+func (c *Cache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	now := c.now()
+	var entries []*cacheEntry
+	// Oldest first, so they are evicted first once loaded again
+	for el := c.lru.Back(); el != nil; el = el.Prev() {
+		if e := el.Value.(*cacheEntry); now.Before(e.Expires) {
+			entries = append(entries, e)
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+	// The cache holds Bugsnag data, so it is only readable by the owner, and replaced atomically
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// Transport returns an http.RoundTripper serving GET requests from the cache, and sending the others to next.
+// Successful requests that change data drop the cached responses of the project they changed.
+func (c *Cache) Transport(next http.RoundTripper) http.RoundTripper {
+	return cacheTransport{cache: c, next: next}
+}
+
+type cacheTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+func (t cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+	if req.Method != http.MethodGet {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < 300 {
+			t.cache.invalidate(path)
+		}
+		return resp, err
+	}
+
+	entity, ok := PathEntityType(path)
+	ttl := t.cache.ttls[entity]
+	if !ok || ttl <= 0 || t.cache.maxEntries <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	if cacheBypassed(req.Context()) {
+		slog.Debug("Bugsnag API cache bypass", slog.String("path", path))
+	} else if e := t.cache.get(key); e != nil {
+		slog.Debug("Bugsnag API cache hit", slog.String("path", path))
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+			StatusCode:    e.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        e.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(e.Body)),
+			ContentLength: int64(len(e.Body)),
+			Request:       req,
+		}, nil
+	} else {
+		slog.Debug("Bugsnag API cache miss", slog.String("path", path))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.cache.put(&cacheEntry{
+		Key:     key,
+		Path:    path,
+		Expires: t.cache.now().Add(ttl),
+		Status:  resp.StatusCode,
+		Header:  resp.Header.Clone(),
+		Body:    body,
+	})
+	return resp, nil
+}
+
+// cacheKey identifies the response to a request. It includes a hash of the credentials, as the same
+// path returns different data for different users.
+func cacheKey(req *http.Request) string {
+	credentials := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(credentials[:8]) + " " + req.URL.String()
+}
+
+// get returns the unexpired entry for key, or nil.
+func (c *Cache) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*cacheEntry)
+	if !c.now().Before(e.Expires) {
+		c.remove(el)
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return e
+}
+
+// put adds or replaces an entry.
+func (c *Cache) put(e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(e)
+}
+
+// add adds or replaces an entry, evicting the least recently used ones over the size limit. c.mu must be held.
+func (c *Cache) add(e *cacheEntry) {
+	if el, ok := c.entries[e.Key]; ok {
+		c.remove(el)
+	}
+	c.entries[e.Key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes an entry. c.mu must be held.
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).Key)
+}
+
+// invalidate drops the entries of the project a request changed, or all entries if it is not scoped to a project.
+func (c *Cache) invalidate(path string) {
+	prefix := ""
+	if projectID := pathSegmentAfter(&url.URL{Path: path}, "projects"); projectID != "" {
+		prefix = "/projects/" + projectID
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if e := el.Value.(*cacheEntry); prefix == "" || strings.Contains(e.Path+"/", prefix+"/") {
+			c.remove(el)
+		}
+		el = next
+	}
+	slog.Debug("Bugsnag API cache invalidated", slog.String("path", path))
+}
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a copy of ctx whose Bugsnag API requests skip the cache, refreshing it with the responses.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
@@ -0,0 +1,189 @@
+package bugsnag
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// setupCachedServer serves the request count from every path, through a cache with the given options.
+func setupCachedServer(t *testing.T, opts CacheOptions) (*Cache, *http.Client, string, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Request", r.URL.Path)
+		io.WriteString(w, r.Method+" "+r.URL.Path+" #"+strconv.Itoa(requests))
+	}))
+	t.Cleanup(server.Close)
+
+	if opts.TTLs == nil {
+		opts.TTLs = DefaultCacheTTLs
+	}
+	cache, err := NewCache(opts)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	return cache, &http.Client{Transport: cache.Transport(http.DefaultTransport)}, server.URL, &requests
+}
+
+// fetch sends a request with the given auth token and returns the response body.
+func fetch(t *testing.T, ctx context.Context, client *http.Client, method, url, token string) string {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() error = %v", err)
+	}
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("client.Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	return string(body)
+}
+
+func TestPathEntityType(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   EntityType
+		wantOK bool
+	}{
+		{path: "/user/organizations", want: EntityOrganizations, wantOK: true},
+		{path: "/organizations/abc/projects", want: EntityProjects, wantOK: true},
+		{path: "/projects/abc/errors", want: EntityErrors, wantOK: true},
+		{path: "/projects/abc/errors/def/events", want: EntityEvents, wantOK: true},
+		{path: "/projects/abc/errors/def/trend", want: EntityTrends, wantOK: true},
+		{path: "/projects/abc/pivots/app.version/values", want: EntityPivots, wantOK: true},
+		{path: "/releases/abc", want: EntityReleases, wantOK: true},
+		{path: "/user", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := PathEntityType(tt.path)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("PathEntityType(%q) = %q, %t, want %q, %t", tt.path, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCacheTransport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves repeated reads from the cache", func(t *testing.T) {
+		_, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10})
+		first := fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a")
+		if got := fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a"); got != first {
+			t.Errorf("second read = %q, want the cached %q", got, first)
+		}
+		if *requests != 1 {
+			t.Errorf("requests = %d, want 1", *requests)
+		}
+	})
+
+	t.Run("caches the responses of each token separately", func(t *testing.T) {
+		_, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10})
+		fetch(t, ctx, client, http.MethodGet, base+"/user/organizations", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/user/organizations", "b")
+		if *requests != 2 {
+			t.Errorf("requests = %d, want 2", *requests)
+		}
+	})
+
+	t.Run("bypass refreshes the cache", func(t *testing.T) {
+		_, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10})
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a")
+		fresh := fetch(t, WithCacheBypass(ctx), client, http.MethodGet, base+"/projects/p1/errors", "a")
+		if got := fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a"); got != fresh {
+			t.Errorf("read after bypass = %q, want the refreshed %q", got, fresh)
+		}
+		if *requests != 2 {
+			t.Errorf("requests = %d, want 2", *requests)
+		}
+	})
+
+	t.Run("expires entries after their TTL", func(t *testing.T) {
+		cache, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10})
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors/e1/events", "a")
+
+		now = now.Add(2 * time.Minute)
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors/e1/events", "a")
+		if *requests != 3 {
+			t.Errorf("requests = %d, want the errors to expire and the events to be cached", *requests)
+		}
+	})
+
+	t.Run("does not cache entity types without a TTL", func(t *testing.T) {
+		_, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10, TTLs: map[EntityType]time.Duration{EntityErrors: 0}})
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors", "a")
+		if *requests != 2 {
+			t.Errorf("requests = %d, want 2", *requests)
+		}
+	})
+
+	t.Run("evicts the least recently used entries", func(t *testing.T) {
+		_, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 2})
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p2", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p3", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1", "a")
+		if *requests != 3 {
+			t.Fatalf("requests = %d, want p1 to stay cached", *requests)
+		}
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p2", "a")
+		if *requests != 4 {
+			t.Errorf("requests = %d, want p2 to be evicted", *requests)
+		}
+	})
+
+	t.Run("changes invalidate the entries of the project", func(t *testing.T) {
+		_, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10})
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors/e1", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p10/errors/e1", "a")
+		fetch(t, ctx, client, http.MethodPatch, base+"/projects/p1/errors/e1", "b")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors/e1", "a")
+		fetch(t, ctx, client, http.MethodGet, base+"/projects/p10/errors/e1", "a")
+		if *requests != 4 {
+			t.Errorf("requests = %d, want only p1 to be fetched again", *requests)
+		}
+	})
+}
+
+func TestCachePersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache, client, base, requests := setupCachedServer(t, CacheOptions{MaxEntries: 10, Path: path})
+	first := fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors/e1/events", "a")
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := NewCache(CacheOptions{TTLs: DefaultCacheTTLs, MaxEntries: 10, Path: path})
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	client = &http.Client{Transport: loaded.Transport(http.DefaultTransport)}
+	if got := fetch(t, ctx, client, http.MethodGet, base+"/projects/p1/errors/e1/events", "a"); got != first {
+		t.Errorf("read after loading = %q, want the persisted %q", got, first)
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+}
@@ -0,0 +1,163 @@
+package bugsnag
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// Filter comparison types supported by the Bugsnag filters query syntax.
+const (
+	FilterTypeEqual    = "eq"
+	FilterTypeNotEqual = "ne"
+)
+
+// ParseFilterExpression parses a filter expression into Bugsnag filters.
+// An expression is a whitespace separated list of terms of the form field=value or field!=value,
+// e.g. `error.status=open event.class="No Method Error" app.release_stage!=development`.
+// Values containing whitespace can be wrapped in double quotes.
+func ParseFilterExpression(expr string) ([]bugsnagAPI.Filter, error) {
+	terms, err := splitTerms(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]bugsnagAPI.Filter, 0, len(terms))
+	for _, term := range terms {
+		key, filterType, value, ok := cutOperator(term)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid filter term %q: expected field=value or field!=value", term)
+		}
+		value = strings.Trim(value, `"`)
+		if value == "" {
+			return nil, fmt.Errorf("invalid filter term %q: missing value", term)
+		}
+		filters = append(filters, bugsnagAPI.Filter{Key: key, Type: filterType, Value: value})
+	}
+	return filters, nil
+}
+
+// cutOperator splits a filter term at its first = or != outside double quotes, so operators inside
+// quoted values are kept in the value.
+func cutOperator(term string) (key, filterType, value string, ok bool) {
+	inQuotes := false
+	for i, r := range term {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '=' && !inQuotes:
+			if i > 0 && term[i-1] == '!' {
+				return term[:i-1], FilterTypeNotEqual, term[i+1:], true
+			}
+			return term[:i], FilterTypeEqual, term[i+1:], true
+		}
+	}
+	return "", "", "", false
+}
+
+// splitTerms splits a filter expression on whitespace, keeping double quoted values together.
+func splitTerms(expr string) ([]string, error) {
+	var terms []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t' || r == '\n') && !inQuotes:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in filter expression: %s", expr)
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms, nil
+}
+
+// EncodeFilters encodes filters into the Bugsnag filters query syntax,
+// e.g. filters[event.class][][type]=eq&filters[event.class][][value]=NoMethodError.
+// Multiple filters on the same field are OR'ed together by Bugsnag.
+func EncodeFilters(filters []bugsnagAPI.Filter) url.Values {
+	values := url.Values{}
+	for _, f := range filters {
+		values.Add("filters["+f.Key+"][][type]", f.Type)
+		values.Add("filters["+f.Key+"][][value]", f.Value)
+	}
+	return values
+}
+
+// FormatFilters renders filters back into a filter expression, sorted by field for stable output.
+func FormatFilters(filters []bugsnagAPI.Filter) string {
+	terms := make([]string, 0, len(filters))
+	for _, f := range filters {
+		op := "="
+		if f.Type == FilterTypeNotEqual {
+			op = "!="
+		}
+		value := f.Value
+		if strings.ContainsAny(value, " \t\n") {
+			value = `"` + value + `"`
+		}
+		terms = append(terms, f.Key+op+value)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, " ")
+}
+
+// ParseTime parses an absolute RFC 3339 timestamp or a time relative to now, such as "30m", "24h", "7d" or "2w".
+// Relative times are interpreted as that long before now.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	d, err := ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected an RFC 3339 timestamp or a relative time like 24h or 7d", value)
+	}
+	return now.Add(-d), nil
+}
+
+// ParseDuration parses a duration made of a whole number and a unit, m, h, d or w, such as "30m" or "7d".
+// These are the units of the relative times accepted by ParseTime.
+func ParseDuration(value string) (time.Duration, error) {
+	if len(value) < 2 {
+		return 0, fmt.Errorf("invalid duration %q: expected a number and a unit, like 24h or 7d", value)
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q: expected a number and a unit, like 24h or 7d", value)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid duration %q: the unit must be m, h, d or w", value)
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,208 @@
+package bugsnag
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    []bugsnagAPI.Filter
+		wantErr bool
+	}{
+		{
+			name: "single equality",
+			expr: "event.class=NoMethodError",
+			want: []bugsnagAPI.Filter{{Key: "event.class", Type: "eq", Value: "NoMethodError"}},
+		},
+		{
+			name: "multiple terms with not equal",
+			expr: "error.status=open  app.release_stage!=development",
+			want: []bugsnagAPI.Filter{
+				{Key: "error.status", Type: "eq", Value: "open"},
+				{Key: "app.release_stage", Type: "ne", Value: "development"},
+			},
+		},
+		{
+			name: "quoted value with spaces",
+			expr: `event.message="undefined method foo" user.id=42`,
+			want: []bugsnagAPI.Filter{
+				{Key: "event.message", Type: "eq", Value: "undefined method foo"},
+				{Key: "user.id", Type: "eq", Value: "42"},
+			},
+		},
+		{
+			name: "value containing equals",
+			expr: "request.url=/search?q=a",
+			want: []bugsnagAPI.Filter{{Key: "request.url", Type: "eq", Value: "/search?q=a"}},
+		},
+		{
+			name: "not equal with value containing equals",
+			expr: "request.url!=/search?q=a",
+			want: []bugsnagAPI.Filter{{Key: "request.url", Type: "ne", Value: "/search?q=a"}},
+		},
+		{
+			name: "value containing not equal",
+			expr: "request.url=/search?q!=a",
+			want: []bugsnagAPI.Filter{{Key: "request.url", Type: "eq", Value: "/search?q!=a"}},
+		},
+		{
+			name: "quoted value containing not equal",
+			expr: `error.message="a!=b"`,
+			want: []bugsnagAPI.Filter{{Key: "error.message", Type: "eq", Value: "a!=b"}},
+		},
+		{
+			name: "quoted value containing operators with not equal",
+			expr: `error.message!="x = y != z"`,
+			want: []bugsnagAPI.Filter{{Key: "error.message", Type: "ne", Value: "x = y != z"}},
+		},
+		{
+			name:    "missing field with not equal",
+			expr:    "!=development",
+			wantErr: true,
+		},
+		{
+			name: "empty expression",
+			expr: "  ",
+			want: []bugsnagAPI.Filter{},
+		},
+		{
+			name:    "missing operator",
+			expr:    "event.class",
+			wantErr: true,
+		},
+		{
+			name:    "missing field",
+			expr:    "=NoMethodError",
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			expr:    "event.class=",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			expr:    `event.message="undefined method`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilterExpression(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFilterExpression() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilterExpression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFilters(t *testing.T) {
+	filters := []bugsnagAPI.Filter{
+		{Key: "event.class", Type: "eq", Value: "NoMethodError"},
+		{Key: "event.class", Type: "eq", Value: "TypeError"},
+		{Key: "app.release_stage", Type: "ne", Value: "development"},
+	}
+	want := url.Values{
+		"filters[event.class][][type]":        {"eq", "eq"},
+		"filters[event.class][][value]":       {"NoMethodError", "TypeError"},
+		"filters[app.release_stage][][type]":  {"ne"},
+		"filters[app.release_stage][][value]": {"development"},
+	}
+	if got := EncodeFilters(filters); !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeFilters() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatFilters(t *testing.T) {
+	filters := []bugsnagAPI.Filter{
+		{Key: "event.message", Type: "eq", Value: "undefined method foo"},
+		{Key: "app.release_stage", Type: "ne", Value: "development"},
+	}
+	want := `app.release_stage!=development event.message="undefined method foo"`
+	if got := FormatFilters(filters); got != want {
+		t.Errorf("FormatFilters() = %v, want %v", got, want)
+	}
+
+	parsed, err := ParseFilterExpression(want)
+	if err != nil {
+		t.Fatalf("ParseFilterExpression() error = %v", err)
+	}
+	if got := FormatFilters(parsed); got != want {
+		t.Errorf("FormatFilters(ParseFilterExpression()) = %v, want %v", got, want)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "rfc3339", value: "2025-05-01T08:30:00Z", want: time.Date(2025, 5, 1, 8, 30, 0, 0, time.UTC)},
+		{name: "date only", value: "2025-05-01", want: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "minutes", value: "30m", want: now.Add(-30 * time.Minute)},
+		{name: "hours", value: "24h", want: now.Add(-24 * time.Hour)},
+		{name: "days", value: "7d", want: now.AddDate(0, 0, -7)},
+		{name: "weeks", value: "2w", want: now.AddDate(0, 0, -14)},
+		{name: "unknown unit", value: "3y", wantErr: true},
+		{name: "not a number", value: "xd", wantErr: true},
+		{name: "negative", value: "-1d", wantErr: true},
+		{name: "too short", value: "d", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "30m", want: 30 * time.Minute},
+		{value: "6h", want: 6 * time.Hour},
+		{value: "1d", want: 24 * time.Hour},
+		{value: "2w", want: 14 * 24 * time.Hour},
+		{value: "0h"},
+		{value: "banana", wantErr: true},
+		{value: "1s", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseDuration() = %v, %v, want %v and an error %t", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package bugsnag
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// LinkKind is the kind of entity a dashboard link points to.
+type LinkKind string
+
+// Kinds of dashboard links understood by ParseLink.
+const (
+	LinkOrganization LinkKind = "organization"
+	LinkProject      LinkKind = "project"
+	LinkErrors       LinkKind = "errors"
+	LinkError        LinkKind = "error"
+	LinkEvent        LinkKind = "event"
+	LinkReleases     LinkKind = "releases"
+	LinkRelease      LinkKind = "release"
+	LinkSavedSearch  LinkKind = "saved_search"
+)
+
+// savedSearchParam is the query parameter of an errors link that selects a saved search.
+const savedSearchParam = "saved_search_id"
+
+// filterParamPattern matches the filter query parameters of a dashboard or API URL, in any of the forms
+// filters[field], filters[field][N], filters[field][] and filters[field][N or empty][type or value].
+var filterParamPattern = regexp.MustCompile(`^filters\[([^\]]+)\](\[(\d*)\])?(\[(type|value)\])?$`)
+
+// Link is a parsed Bugsnag dashboard or API link.
+type Link struct {
+	Kind             LinkKind `json:"kind"`
+	OrganizationSlug string   `json:"organization_slug,omitempty"`
+	ProjectSlug      string   `json:"project_slug,omitempty"`
+	// OrganizationID and ProjectID are set instead of the slugs by API links, which use IDs.
+	OrganizationID string              `json:"organization_id,omitempty"`
+	ProjectID      string              `json:"project_id,omitempty"`
+	ErrorID        string              `json:"error_id,omitempty"`
+	EventID        string              `json:"event_id,omitempty"`
+	ReleaseID      string              `json:"release_id,omitempty"`
+	SavedSearchID  string              `json:"saved_search_id,omitempty"`
+	Filters        []bugsnagAPI.Filter `json:"-"`
+}
+
+// IsLink reports whether ref is meant as a link rather than an ID, slug or name.
+func IsLink(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")
+}
+
+// ParseIDOrLink parses ref as a dashboard link with ParseLink, or returns nil when it is a plain ID.
+// IDs never contain a slash, so a ref with one is taken as a link too, and rejected if it is not one.
+func ParseIDOrLink(ref string) (*Link, error) {
+	if !IsLink(ref) && !strings.Contains(ref, "/") {
+		return nil, nil
+	}
+	return ParseLink(ref)
+}
+
+// ErrorIDFromIDOrLink returns the ID of the error referred to by ref, which can be a plain ID or a
+// dashboard link to the error or one of its events.
+func ErrorIDFromIDOrLink(ref string) (string, error) {
+	link, err := ParseIDOrLink(ref)
+	if err != nil {
+		return "", err
+	}
+	if link == nil {
+		return ref, nil
+	}
+	if link.ErrorID == "" {
+		return "", fmt.Errorf("link %s does not point to an error", ref)
+	}
+	return link.ErrorID, nil
+}
+
+// ParseLink parses a dashboard link of the form {host}/{organization}/{project}/{section}/{id}?{query},
+// e.g. https://app.bugsnag.com/acme/web/errors/5f1a...?event_id=5f1b...&filters[event.since]=30d.
+// Links to errors, events, releases and saved searches are recognised; any other project page
+// is treated as a link to the project. API links of the form {host}/projects/{id}/{section}/{id}
+// and {host}/organizations/{id} are recognised too. Filters embedded in the query are parsed as well.
+func ParseLink(raw string) (*Link, error) {
+	u, err := url.Parse(raw)
+	if err != nil || !IsLink(raw) || u.Host == "" {
+		return nil, fmt.Errorf("invalid link %q: expected an absolute http(s) URL", raw)
+	}
+	filters, err := ParseURLFilters(u.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	segments := pathSegments(u)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid link %q: no organization in path", raw)
+	}
+	link := &Link{Kind: LinkOrganization, Filters: filters}
+	switch {
+	case segments[0] == "organizations" && len(segments) > 1:
+		link.OrganizationID = segments[1]
+		return link, nil
+	case segments[0] == "projects" && len(segments) > 1:
+		link.ProjectID = segments[1]
+		segments = segments[2:]
+	case len(segments) == 1:
+		link.OrganizationSlug = segments[0]
+		return link, nil
+	default:
+		link.OrganizationSlug, link.ProjectSlug = segments[0], segments[1]
+		segments = segments[2:]
+	}
+
+	link.Kind = LinkProject
+	section, id := "", ""
+	if len(segments) > 0 {
+		section = segments[0]
+	}
+	if len(segments) > 1 {
+		id = segments[1]
+	}
+
+	switch section {
+	case "errors":
+		switch {
+		case id != "" && u.Query().Get("event_id") != "":
+			link.Kind = LinkEvent
+			link.ErrorID = id
+			link.EventID = u.Query().Get("event_id")
+		case id != "":
+			link.Kind = LinkError
+			link.ErrorID = id
+		case u.Query().Get(savedSearchParam) != "":
+			link.Kind = LinkSavedSearch
+			link.SavedSearchID = u.Query().Get(savedSearchParam)
+		default:
+			link.Kind = LinkErrors
+		}
+	case "events":
+		if id != "" && link.ProjectID != "" {
+			link.Kind = LinkEvent
+			link.EventID = id
+		}
+	case "releases":
+		link.Kind = LinkReleases
+		if id != "" {
+			link.Kind = LinkRelease
+			link.ReleaseID = id
+		}
+	}
+	return link, nil
+}
+
+// pathSegments returns the non-empty segments of the URL path.
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// pathSegmentAfter returns the path segment following name, as in API URLs like /projects/{id},
+// or an empty string if there is none.
+func pathSegmentAfter(u *url.URL, name string) string {
+	segments := pathSegments(u)
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == name {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// ParseURLFilters extracts the filters from the query parameters of a dashboard or API URL.
+// Filters without a type are equality filters. They are returned sorted by field.
+func ParseURLFilters(query url.Values) ([]bugsnagAPI.Filter, error) {
+	type slot struct {
+		field string
+		index int
+	}
+	parsed := map[slot]*bugsnagAPI.Filter{}
+	for key, values := range query {
+		m := filterParamPattern.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+		field, hasIndex, index, attr := m[1], m[2] != "" && m[3] != "", m[3], m[5]
+
+		for i, value := range values {
+			s := slot{field: field, index: i}
+			if hasIndex {
+				s.index, _ = strconv.Atoi(index)
+			}
+			f, ok := parsed[s]
+			if !ok {
+				f = &bugsnagAPI.Filter{Key: field}
+				parsed[s] = f
+			}
+			if attr == "type" {
+				f.Type = value
+			} else {
+				f.Value = value
+			}
+		}
+	}
+
+	slots := make([]slot, 0, len(parsed))
+	for s := range parsed {
+		slots = append(slots, s)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].field != slots[j].field {
+			return slots[i].field < slots[j].field
+		}
+		return slots[i].index < slots[j].index
+	})
+
+	filters := make([]bugsnagAPI.Filter, 0, len(slots))
+	for _, s := range slots {
+		f := parsed[s]
+		if f.Value == "" {
+			return nil, fmt.Errorf("filter %s has no value", f.Key)
+		}
+		if f.Type == "" {
+			f.Type = FilterTypeEqual
+		}
+		filters = append(filters, *f)
+	}
+	return filters, nil
+}
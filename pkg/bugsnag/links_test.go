@@ -0,0 +1,257 @@
+package bugsnag
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		link      string
+		want      *Link
+		wantError bool
+	}{
+		{
+			name: "organization",
+			link: "https://app.bugsnag.com/acme/",
+			want: &Link{Kind: LinkOrganization, OrganizationSlug: "acme", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "project overview",
+			link: "https://app.bugsnag.com/acme/web/overview",
+			want: &Link{Kind: LinkProject, OrganizationSlug: "acme", ProjectSlug: "web", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "errors list with filters",
+			link: "https://app.bugsnag.com/acme/web/errors?filters[event.since][0]=30d&filters[error.status][0]=open",
+			want: &Link{Kind: LinkErrors, OrganizationSlug: "acme", ProjectSlug: "web", Filters: []bugsnagAPI.Filter{
+				{Key: "error.status", Type: "eq", Value: "open"},
+				{Key: "event.since", Type: "eq", Value: "30d"},
+			}},
+		},
+		{
+			name: "error",
+			link: "https://app.bugsnag.com/acme/web/errors/errid?filters[event.since]=30d",
+			want: &Link{Kind: LinkError, OrganizationSlug: "acme", ProjectSlug: "web", ErrorID: "errid", Filters: []bugsnagAPI.Filter{
+				{Key: "event.since", Type: "eq", Value: "30d"},
+			}},
+		},
+		{
+			name: "event",
+			link: "https://app.bugsnag.com/acme/web/errors/errid?event_id=evid",
+			want: &Link{Kind: LinkEvent, OrganizationSlug: "acme", ProjectSlug: "web", ErrorID: "errid", EventID: "evid", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "saved search",
+			link: "https://app.bugsnag.com/acme/web/errors?saved_search_id=ssid",
+			want: &Link{Kind: LinkSavedSearch, OrganizationSlug: "acme", ProjectSlug: "web", SavedSearchID: "ssid", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "releases",
+			link: "http://localhost:8080/acme/web/releases",
+			want: &Link{Kind: LinkReleases, OrganizationSlug: "acme", ProjectSlug: "web", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "release",
+			link: "https://app.bugsnag.com/acme/web/releases/relid",
+			want: &Link{Kind: LinkRelease, OrganizationSlug: "acme", ProjectSlug: "web", ReleaseID: "relid", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "API project",
+			link: "https://api.bugsnag.com/projects/pid/errors?filters[error.status][][type]=eq&filters[error.status][][value]=open",
+			want: &Link{Kind: LinkErrors, ProjectID: "pid", Filters: []bugsnagAPI.Filter{{Key: "error.status", Type: "eq", Value: "open"}}},
+		},
+		{
+			name: "API event",
+			link: "https://api.bugsnag.com/projects/pid/events/evid",
+			want: &Link{Kind: LinkEvent, ProjectID: "pid", EventID: "evid", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name: "API organization",
+			link: "https://api.bugsnag.com/organizations/oid",
+			want: &Link{Kind: LinkOrganization, OrganizationID: "oid", Filters: []bugsnagAPI.Filter{}},
+		},
+		{
+			name:      "not a URL",
+			link:      "acme/web",
+			wantError: true,
+		},
+		{
+			name:      "no organization",
+			link:      "https://app.bugsnag.com/",
+			wantError: true,
+		},
+		{
+			name:      "no host",
+			link:      "http:///acme/web",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLink(tt.link)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseLink() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLink() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIDOrLink(t *testing.T) {
+	tests := []struct {
+		ref      string
+		wantLink bool
+		wantErr  bool
+	}{
+		{ref: "5f1a2b3c4d5e6f7a8b9c0d1e"},
+		{ref: "https://app.bugsnag.com/acme/web/errors/5f1a2b3c4d5e6f7a8b9c0d1e", wantLink: true},
+		{ref: "acme/web/errors/5f1a2b3c4d5e6f7a8b9c0d1e", wantErr: true},
+		{ref: "/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			link, err := ParseIDOrLink(tt.ref)
+			if (err != nil) != tt.wantErr || (link != nil) != tt.wantLink {
+				t.Errorf("ParseIDOrLink() = %+v, %v, want a link %t and an error %t", link, err, tt.wantLink, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestErrorIDFromIDOrLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantID    string
+		wantError bool
+	}{
+		{
+			name:      "plain error id",
+			input:     "5f1a2b3c4d5e6f7a8b9c0d1e",
+			wantID:    "5f1a2b3c4d5e6f7a8b9c0d1e",
+			wantError: false,
+		},
+		{
+			name:      "error link",
+			input:     "https://app.bugsnag.com/org/proj/errors/errid",
+			wantID:    "errid",
+			wantError: false,
+		},
+		{
+			name:      "error link with query and trailing slash",
+			input:     "https://app.bugsnag.com/org/proj/errors/errid/?filters[event.since]=30d",
+			wantID:    "errid",
+			wantError: false,
+		},
+		{
+			name:      "event link contains error id",
+			input:     "https://app.bugsnag.com/org/proj/errors/errid/events/event?event_id=xyz789",
+			wantID:    "errid",
+			wantError: false,
+		},
+		{
+			name:      "on-premise host:port error link",
+			input:     "http://localhost:8080/org/proj/errors/local456",
+			wantID:    "local456",
+			wantError: false,
+		},
+		{
+			name:      "errors list link without id",
+			input:     "https://app.bugsnag.com/org/proj/errors",
+			wantID:    "",
+			wantError: true,
+		},
+		{
+			name:      "link without errors segment",
+			input:     "https://app.bugsnag.com/org/proj",
+			wantID:    "",
+			wantError: true,
+		},
+		{
+			name:      "malformed url",
+			input:     "http://%41:8080/",
+			wantID:    "",
+			wantError: true,
+		},
+		{
+			name:      "empty string",
+			input:     "",
+			wantID:    "",
+			wantError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, err := ErrorIDFromIDOrLink(tt.input)
+			if (err != nil) != tt.wantError {
+				t.Errorf("ErrorIDFromIDOrLink() error = %v, wantError %v", err, tt.wantError)
+			}
+			if gotID != tt.wantID {
+				t.Errorf("ErrorIDFromIDOrLink() = %v, want %v", gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestParseURLFilters(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      []bugsnagAPI.Filter
+		wantError bool
+	}{
+		{
+			name:  "value only",
+			query: "filters[event.since]=7d&filters[app.release_stage][0]=production&filters[app.release_stage][1]=staging",
+			want: []bugsnagAPI.Filter{
+				{Key: "app.release_stage", Type: "eq", Value: "production"},
+				{Key: "app.release_stage", Type: "eq", Value: "staging"},
+				{Key: "event.since", Type: "eq", Value: "7d"},
+			},
+		},
+		{
+			name:  "API encoding",
+			query: "filters[user.id][][type]=ne&filters[user.id][][value]=42&filters[user.id][][type]=ne&filters[user.id][][value]=43",
+			want: []bugsnagAPI.Filter{
+				{Key: "user.id", Type: "ne", Value: "42"},
+				{Key: "user.id", Type: "ne", Value: "43"},
+			},
+		},
+		{
+			name:  "indexed type and value",
+			query: "filters[error.status][0][type]=eq&filters[error.status][0][value]=open&sort=last_seen",
+			want:  []bugsnagAPI.Filter{{Key: "error.status", Type: "eq", Value: "open"}},
+		},
+		{
+			name:      "type without value",
+			query:     "filters[error.status][0][type]=eq",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("url.ParseQuery() error = %v", err)
+			}
+			got, err := ParseURLFilters(query)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseURLFilters() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseURLFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package bugsnag
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+const (
+	// MaxPerPage is the largest page size accepted by the Bugsnag API.
+	MaxPerPage = 100
+	// MaxItemsLimit is the hard cap on the number of items a single auto-paginated list can return.
+	MaxItemsLimit = 1000
+
+	totalCountHeader = "X-Total-Count"
+)
+
+// PageOptions controls which page of a list is retrieved.
+type PageOptions struct {
+	// PerPage is the number of items to request per page. Zero uses the API default.
+	PerPage int
+	// Cursor continues a previous list from the page it points to.
+	Cursor string
+	// MaxItems enables auto-pagination: pages are followed until this many items have been retrieved
+	// or there are no more pages. It is capped at MaxItemsLimit. Zero retrieves a single page.
+	MaxItems int
+}
+
+// PageInfo describes where a list stopped and how to continue it.
+type PageInfo struct {
+	// NextCursor retrieves the next page when passed back in PageOptions.Cursor. Empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalCount is the total number of items in the list, when reported by the API.
+	TotalCount int `json:"total_count,omitempty"`
+}
+
+// List performs a GET request against the given API path with the raw query parameters and
+// decodes the response into v. It is used for list endpoints whose query parameters, such as
+// filters, cannot be expressed with the option structs of the API client.
+func List(ctx context.Context, client *bugsnagAPI.Client, path string, query url.Values, v any) (*http.Response, error) {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := client.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(ctx, req, v)
+}
+
+// Get performs a GET request for a single object at the given API path and decodes the response into v.
+func Get(ctx context.Context, client *bugsnagAPI.Client, path string, v any) error {
+	req, err := client.NewRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(ctx, req, v)
+	return err
+}
+
+// ListPage retrieves items from a paginated list endpoint, following the Link header of each
+// response. It returns the items along with a cursor for the next page, if there is one.
+// A cursor carries the path and query of the list it continues: it is rejected for another path,
+// and query must be empty or the same as the one the list was started with.
+func ListPage[T any](ctx context.Context, client *bugsnagAPI.Client, path string, query url.Values, opts PageOptions) ([]T, *PageInfo, error) {
+	var origin url.Values
+	if opts.Cursor != "" {
+		cursor, err := DecodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cursor.Path != path {
+			return nil, nil, fmt.Errorf("invalid cursor: it continues the list of %s, not %s; start again without a cursor", cursor.Path, path)
+		}
+		if len(query) > 0 && !sameQuery(query, cursor.Query) {
+			return nil, nil, fmt.Errorf("invalid cursor: filter and sort arguments cannot be changed when continuing a list; repeat those of the first page or leave them out")
+		}
+		origin, query = cursor.Query, cursor.Next
+	} else {
+		origin, query = cloneValues(query), cloneValues(query)
+	}
+
+	perPage := min(opts.PerPage, MaxPerPage)
+	maxItems := min(opts.MaxItems, MaxItemsLimit)
+	if maxItems > 0 && perPage <= 0 {
+		perPage = MaxPerPage
+	}
+
+	var items []T
+	info := &PageInfo{}
+	for {
+		if perPage > 0 {
+			size := perPage
+			if maxItems > 0 {
+				size = min(size, maxItems-len(items))
+			}
+			query.Set("per_page", strconv.Itoa(size))
+		}
+
+		var page []T
+		resp, err := List(ctx, client, path, query, &page)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, page...)
+		if total, err := strconv.Atoi(resp.Header.Get(totalCountHeader)); err == nil {
+			info.TotalCount = total
+		}
+
+		next := nextPageQuery(resp.Header)
+		if next == nil {
+			info.NextCursor = ""
+			break
+		}
+		if opts.PerPage > 0 {
+			next.Set("per_page", strconv.Itoa(min(opts.PerPage, MaxPerPage)))
+		} else {
+			next.Del("per_page")
+		}
+		info.NextCursor = EncodeCursor(Cursor{Path: path, Query: origin, Next: next})
+
+		if maxItems <= 0 || len(items) >= maxItems || len(page) == 0 {
+			break
+		}
+		query = next
+	}
+
+	if maxItems > 0 && len(items) > maxItems {
+		items = items[:maxItems]
+	}
+	return items, info, nil
+}
+
+// Cursor is the decoded content of a cursor.
+type Cursor struct {
+	// Path is the API path of the list the cursor continues.
+	Path string `json:"path"`
+	// Query is the query the list was started with, without the page size.
+	Query url.Values `json:"query,omitempty"`
+	// Next is the query of the next page.
+	Next url.Values `json:"next"`
+}
+
+// EncodeCursor encodes a cursor into an opaque string.
+func EncodeCursor(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor decodes a cursor created by EncodeCursor.
+func DecodeCursor(cursor string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Path == "" {
+		return nil, fmt.Errorf("invalid cursor: not returned by a list")
+	}
+	if c.Next == nil {
+		c.Next = url.Values{}
+	}
+	return &c, nil
+}
+
+// timeFilterParams are the query parameters of the time range filters. Relative times such as
+// "24h" resolve to a different timestamp on every call, so they are left out when a query is
+// compared with the one saved in a cursor, and the list keeps the range it was started with.
+var timeFilterParams = EncodeFilters([]bugsnagAPI.Filter{
+	{Key: "event.since", Type: FilterTypeEqual},
+	{Key: "event.before", Type: FilterTypeEqual},
+})
+
+// sameQuery reports whether two queries have the same parameters, ignoring the page size and time range.
+func sameQuery(a, b url.Values) bool {
+	a, b = cloneValues(a), cloneValues(b)
+	for _, values := range []url.Values{a, b} {
+		values.Del("per_page")
+		for param := range timeFilterParams {
+			values.Del(param)
+		}
+	}
+	return maps.EqualFunc(a, b, slices.Equal)
+}
+
+// nextPageQuery returns the query parameters of the rel="next" link in the Link header, or nil if there is none.
+func nextPageQuery(header http.Header) url.Values {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		isNext := false
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "rel" && strings.Trim(value, `"`) == "next" {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return nil
+		}
+		return u.Query()
+	}
+	return nil
+}
+
+// cloneValues returns a copy of the query parameters that can be modified safely.
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for k, v := range values {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
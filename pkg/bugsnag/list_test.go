@@ -0,0 +1,257 @@
+package bugsnag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// setupPagedServer serves total numbered items from /items using offset pagination and Link headers.
+func setupPagedServer(t *testing.T, total int) (*bugsnagAPI.Client, *[]url.Values) {
+	t.Helper()
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requests = append(requests, query)
+
+		perPage := 30
+		if v := query.Get("per_page"); v != "" {
+			perPage, _ = strconv.Atoi(v)
+		}
+		offset, _ := strconv.Atoi(query.Get("offset"))
+
+		var items []int
+		for i := offset; i < total && i < offset+perPage; i++ {
+			items = append(items, i)
+		}
+		if offset+perPage < total {
+			next := url.Values{}
+			for k, v := range query {
+				next[k] = v
+			}
+			next.Set("offset", strconv.Itoa(offset+perPage))
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/items?%s>; rel="next"`, r.Host, next.Encode()))
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		_ = json.NewEncoder(w).Encode(items)
+	}))
+	t.Cleanup(server.Close)
+
+	return bugsnagAPI.NewClient("test-token", bugsnagAPI.WithBaseURL(server.URL)), &requests
+}
+
+func TestListPage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("single page returns a cursor", func(t *testing.T) {
+		client, _ := setupPagedServer(t, 5)
+		items, info, err := ListPage[int](ctx, client, "items", url.Values{"sort": {"last_seen"}}, PageOptions{PerPage: 2})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		if want := []int{0, 1}; !reflect.DeepEqual(items, want) {
+			t.Errorf("ListPage() items = %v, want %v", items, want)
+		}
+		if info.NextCursor == "" || info.TotalCount != 5 {
+			t.Errorf("ListPage() info = %+v, want a next cursor and total 5", info)
+		}
+
+		// Continue from the cursor; the original query is carried by it
+		items, info, err = ListPage[int](ctx, client, "items", nil, PageOptions{Cursor: info.NextCursor, PerPage: 2})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		if want := []int{2, 3}; !reflect.DeepEqual(items, want) {
+			t.Errorf("ListPage() items = %v, want %v", items, want)
+		}
+		cursor, err := DecodeCursor(info.NextCursor)
+		if err != nil {
+			t.Fatalf("DecodeCursor() error = %v", err)
+		}
+		if query := cursor.Next; query.Get("sort") != "last_seen" || query.Get("offset") != "4" {
+			t.Errorf("cursor query = %v, want sort and offset carried over", query)
+		}
+		if cursor.Path != "items" || cursor.Query.Get("sort") != "last_seen" {
+			t.Errorf("cursor = %+v, want the path and query of the list", cursor)
+		}
+
+		// Repeating the original query is accepted
+		if items, _, err = ListPage[int](ctx, client, "items", url.Values{"sort": {"last_seen"}}, PageOptions{Cursor: info.NextCursor, PerPage: 2}); err != nil || len(items) != 1 {
+			t.Errorf("ListPage() with the original query = %v, %v, want the last item", items, err)
+		}
+	})
+
+	t.Run("cursor of another list", func(t *testing.T) {
+		client, requests := setupPagedServer(t, 5)
+		_, info, err := ListPage[int](ctx, client, "items", url.Values{"sort": {"last_seen"}}, PageOptions{PerPage: 2})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+
+		if _, _, err := ListPage[int](ctx, client, "other", nil, PageOptions{Cursor: info.NextCursor}); err == nil || !strings.Contains(err.Error(), "continues the list of items") {
+			t.Errorf("ListPage() on another path error = %v, want the cursor rejected", err)
+		}
+		if _, _, err := ListPage[int](ctx, client, "items", url.Values{"sort": {"first_seen"}}, PageOptions{Cursor: info.NextCursor}); err == nil || !strings.Contains(err.Error(), "cannot be changed") {
+			t.Errorf("ListPage() with another query error = %v, want the cursor rejected", err)
+		}
+		if len(*requests) != 1 {
+			t.Errorf("requests = %v, want none for rejected cursors", *requests)
+		}
+	})
+
+	t.Run("time range may be recomputed", func(t *testing.T) {
+		client, _ := setupPagedServer(t, 5)
+		since := func(value string) url.Values {
+			return EncodeFilters([]bugsnagAPI.Filter{{Key: "event.since", Type: FilterTypeEqual, Value: value}})
+		}
+		_, info, err := ListPage[int](ctx, client, "items", since("2026-01-01T00:00:00Z"), PageOptions{PerPage: 2})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+
+		items, _, err := ListPage[int](ctx, client, "items", since("2026-01-01T00:00:01Z"), PageOptions{Cursor: info.NextCursor, PerPage: 2})
+		if err != nil {
+			t.Fatalf("ListPage() with a recomputed since error = %v", err)
+		}
+		if want := []int{2, 3}; !reflect.DeepEqual(items, want) {
+			t.Errorf("ListPage() items = %v, want %v", items, want)
+		}
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		client, _ := setupPagedServer(t, 2)
+		items, info, err := ListPage[int](ctx, client, "items", nil, PageOptions{})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		if len(items) != 2 || info.NextCursor != "" {
+			t.Errorf("ListPage() = %v, %+v, want 2 items and no cursor", items, info)
+		}
+	})
+
+	t.Run("auto-paginates up to max items", func(t *testing.T) {
+		client, requests := setupPagedServer(t, 250)
+		items, info, err := ListPage[int](ctx, client, "items", nil, PageOptions{MaxItems: 150})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		if len(items) != 150 || items[149] != 149 {
+			t.Errorf("ListPage() returned %d items, want 150", len(items))
+		}
+		if len(*requests) != 2 || (*requests)[1].Get("per_page") != "50" {
+			t.Errorf("requests = %v, want a full page then a page of the remaining 50", *requests)
+		}
+
+		cursor, err := DecodeCursor(info.NextCursor)
+		if err != nil {
+			t.Fatalf("DecodeCursor() error = %v", err)
+		}
+		if query := cursor.Next; query.Get("offset") != "150" || query.Has("per_page") {
+			t.Errorf("cursor query = %v, want offset 150 without the shortened page size", query)
+		}
+	})
+
+	t.Run("auto-pagination stops at the last page", func(t *testing.T) {
+		client, _ := setupPagedServer(t, 120)
+		items, info, err := ListPage[int](ctx, client, "items", nil, PageOptions{MaxItems: 500})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		if len(items) != 120 || info.NextCursor != "" {
+			t.Errorf("ListPage() = %d items, %+v, want 120 items and no cursor", len(items), info)
+		}
+	})
+
+	t.Run("max items is capped", func(t *testing.T) {
+		client, _ := setupPagedServer(t, MaxItemsLimit+50)
+		items, _, err := ListPage[int](ctx, client, "items", nil, PageOptions{MaxItems: MaxItemsLimit * 2})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		if len(items) != MaxItemsLimit {
+			t.Errorf("ListPage() returned %d items, want %d", len(items), MaxItemsLimit)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		client, _ := setupPagedServer(t, 5)
+		if _, _, err := ListPage[int](ctx, client, "items", nil, PageOptions{Cursor: "not a cursor!"}); err == nil {
+			t.Errorf("ListPage() expected an error for an invalid cursor")
+		}
+	})
+}
+
+func TestNextPageQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want url.Values
+	}{
+		{
+			name: "next link",
+			link: `<https://api.bugsnag.com/projects/1/events?offset=30&per_page=30>; rel="next"`,
+			want: url.Values{"offset": {"30"}, "per_page": {"30"}},
+		},
+		{
+			name: "next among other links",
+			link: `<https://api.bugsnag.com/x?offset=0>; rel="prev", <https://api.bugsnag.com/x?offset=60>; rel="next"`,
+			want: url.Values{"offset": {"60"}},
+		},
+		{
+			name: "no next link",
+			link: `<https://api.bugsnag.com/x?offset=0>; rel="prev"`,
+			want: nil,
+		},
+		{
+			name: "no link header",
+			link: "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.link != "" {
+				header.Set("Link", tt.link)
+			}
+			if got := nextPageQuery(header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nextPageQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Path != "/releases/r1" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "r1"})
+	}))
+	t.Cleanup(server.Close)
+	client := bugsnagAPI.NewClient("test-token", bugsnagAPI.WithBaseURL(server.URL))
+
+	var release Release
+	if err := Get(context.Background(), client, "releases/r1", &release); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if release.ID != "r1" || len(query) != 0 {
+		t.Errorf("Get() = %+v with query %v, want the release without list parameters", release, query)
+	}
+	if err := Get(context.Background(), client, "releases/missing", &release); err == nil {
+		t.Errorf("Get() expected an error for a missing object")
+	}
+}
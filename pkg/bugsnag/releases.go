@@ -0,0 +1,44 @@
+package bugsnag
+
+import "time"
+
+// Release is a build of a project deployed to a release stage, as returned by the Bugsnag
+// release endpoints, which the API client does not cover.
+// API docs: https://bugsnagapiv2.docs.apiary.io/#reference/projects/releases
+type Release struct {
+	ID                                  string            `json:"id"`
+	ReleaseTime                         time.Time         `json:"release_time"`
+	ReleaseSource                       string            `json:"release_source"`
+	AppVersion                          string            `json:"app_version"`
+	AppVersionCode                      string            `json:"app_version_code,omitempty"`
+	AppBundleVersion                    string            `json:"app_bundle_version,omitempty"`
+	BuildLabel                          string            `json:"build_label,omitempty"`
+	BuilderName                         string            `json:"builder_name,omitempty"`
+	BuildTool                           string            `json:"build_tool,omitempty"`
+	ErrorsIntroducedCount               int               `json:"errors_introduced_count"`
+	ErrorsSeenCount                     int               `json:"errors_seen_count"`
+	SessionsCountInLast24h              int               `json:"sessions_count_in_last_24h"`
+	TotalSessionsCount                  int               `json:"total_sessions_count"`
+	UnhandledSessionsCount              int               `json:"unhandled_sessions_count"`
+	AccumulativeDailyUsersSeen          int               `json:"accumulative_daily_users_seen"`
+	AccumulativeDailyUsersWithUnhandled int               `json:"accumulative_daily_users_with_unhandled"`
+	Metadata                            map[string]any    `json:"metadata,omitempty"`
+	ReleaseStage                        ReleaseStage      `json:"release_stage"`
+	SourceControl                       *SourceControl    `json:"source_control,omitempty"`
+	ReleaseGroupID                      string            `json:"release_group_id,omitempty"`
+	Links                               map[string]string `json:"links,omitempty"`
+}
+
+// ReleaseStage is the stage, e.g. production, a release was deployed to.
+type ReleaseStage struct {
+	Name string `json:"name"`
+}
+
+// SourceControl describes the revision a release was built from.
+type SourceControl struct {
+	Service            string `json:"service,omitempty"`
+	CommitURL          string `json:"commit_url,omitempty"`
+	Revision           string `json:"revision,omitempty"`
+	DiffURLToPrevious  string `json:"diff_url_to_previous,omitempty"`
+	PreviousAppVersion string `json:"previous_app_version,omitempty"`
+}
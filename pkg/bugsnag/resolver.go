@@ -0,0 +1,329 @@
+package bugsnag
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// idPattern matches the 24 character hex IDs Bugsnag uses for organizations, projects and releases.
+var idPattern = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// IsID reports whether ref has the form of a Bugsnag ID rather than a slug, name or version.
+func IsID(ref string) bool {
+	return idPattern.MatchString(ref)
+}
+
+// minReloadInterval is the minimum time between two reloads of the index forced by references
+// that match nothing, so typos and partially typed names do not list everything again each time.
+const minReloadInterval = time.Minute
+
+// Resolver turns the ways a user refers to an organization or project (an ID, slug, name or
+// dashboard URL) into its ID. The organizations and projects of the current user are listed
+// once and cached for the lifetime of the resolver.
+type Resolver struct {
+	client *bugsnagAPI.Client
+	// now returns the current time, to tell when the index can be reloaded.
+	now func() time.Time
+
+	mu    sync.Mutex
+	index *resolverIndex
+	// loadedAt is when the index was last listed.
+	loadedAt time.Time
+	// loading is the listing in progress, shared by the lookups waiting for it.
+	loading *resolverLoad
+}
+
+// resolverIndex is the cached list of organizations and projects.
+type resolverIndex struct {
+	organizations []*bugsnagAPI.Organization
+	projects      []*bugsnagAPI.Project
+}
+
+// resolverLoad is a listing of the index in progress. done is closed once index or err is set.
+type resolverLoad struct {
+	done  chan struct{}
+	index *resolverIndex
+	err   error
+}
+
+// resolverMatch is an organization or project matching a reference.
+type resolverMatch struct {
+	ID   string
+	Name string
+	// Slug is the slug of an organization, or "organization/project" slugs of a project.
+	Slug string
+}
+
+// NewResolver creates a Resolver that lists organizations and projects with the given client.
+func NewResolver(client *bugsnagAPI.Client) *Resolver {
+	return &Resolver{client: client, now: time.Now}
+}
+
+// Invalidate drops the cached organizations and projects, so they are listed again on the next lookup.
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.index, r.loadedAt = nil, time.Time{}
+}
+
+// ResolveOrganization returns the ID of the organization referred to by ref, which can be an ID,
+// slug, name (case-insensitive) or dashboard URL.
+func (r *Resolver) ResolveOrganization(ctx context.Context, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("empty organization reference")
+	}
+	if idPattern.MatchString(ref) {
+		return ref, nil
+	}
+	if IsLink(ref) {
+		link, err := ParseLink(ref)
+		if err != nil {
+			return "", err
+		}
+		if idPattern.MatchString(link.OrganizationID) {
+			return link.OrganizationID, nil
+		}
+		if link.OrganizationSlug == "" {
+			return "", fmt.Errorf("URL %q does not refer to an organization", ref)
+		}
+		ref = link.OrganizationSlug
+	}
+
+	matches, err := r.lookup(ctx, func(idx *resolverIndex) []resolverMatch {
+		return idx.matchOrganizations(ref)
+	})
+	if err != nil {
+		return "", err
+	}
+	return pickMatch("organization", ref, matches)
+}
+
+// ResolveProject returns the ID of the project referred to by ref, which can be an ID, slug,
+// name (case-insensitive), "organization/project" pair of slugs or names, or dashboard URL. A ref with
+// a slash that matches no organization and project pair is matched as a whole, as a name such as "web/api".
+func (r *Resolver) ResolveProject(ctx context.Context, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("empty project reference")
+	}
+	if idPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	var orgRef, projectRef string
+	split := false
+	if IsLink(ref) {
+		link, err := ParseLink(ref)
+		if err != nil {
+			return "", err
+		}
+		if idPattern.MatchString(link.ProjectID) {
+			return link.ProjectID, nil
+		}
+		if link.ProjectSlug == "" {
+			return "", fmt.Errorf("URL %q does not refer to a project", ref)
+		}
+		orgRef, projectRef = link.OrganizationSlug, link.ProjectSlug
+	} else if org, project, ok := strings.Cut(ref, "/"); ok {
+		orgRef, projectRef = strings.TrimSpace(org), strings.TrimSpace(project)
+		split = true
+	} else {
+		projectRef = ref
+	}
+
+	matches, err := r.lookup(ctx, func(idx *resolverIndex) []resolverMatch {
+		matches := idx.matchProjects(orgRef, projectRef)
+		if len(matches) == 0 && split {
+			// Project names may contain a slash themselves
+			matches = idx.matchProjects("", ref)
+		}
+		return matches
+	})
+	if err != nil {
+		return "", err
+	}
+	return pickMatch("project", ref, matches)
+}
+
+// Organizations returns the organizations of the current user, listing them on first use.
+func (r *Resolver) Organizations(ctx context.Context) ([]*bugsnagAPI.Organization, error) {
+	idx, err := r.cachedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return idx.organizations, nil
+}
+
+// Projects returns the projects of all the organizations of the current user, listing them on first use.
+func (r *Resolver) Projects(ctx context.Context) ([]*bugsnagAPI.Project, error) {
+	idx, err := r.cachedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return idx.projects, nil
+}
+
+// cachedIndex returns the cached index, loading it first if needed.
+func (r *Resolver) cachedIndex(ctx context.Context) (*resolverIndex, error) {
+	r.mu.Lock()
+	idx := r.index
+	r.mu.Unlock()
+	if idx != nil {
+		return idx, nil
+	}
+	return r.load(ctx)
+}
+
+// lookup runs match against the cached index. When nothing matches a cached index, it is listed
+// again in case the organization or project was created after it was cached, unless it was listed
+// less than minReloadInterval ago.
+func (r *Resolver) lookup(ctx context.Context, match func(*resolverIndex) []resolverMatch) ([]resolverMatch, error) {
+	r.mu.Lock()
+	idx, loadedAt := r.index, r.loadedAt
+	r.mu.Unlock()
+
+	cached := idx != nil
+	if !cached {
+		var err error
+		if idx, err = r.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+	matches := match(idx)
+	if len(matches) == 0 && cached && r.now().Sub(loadedAt) >= minReloadInterval {
+		// Skip the response cache too, or the reload would list the same organizations and projects
+		idx, err := r.load(WithCacheBypass(ctx))
+		if err != nil {
+			return nil, err
+		}
+		matches = match(idx)
+	}
+	return matches, nil
+}
+
+// load lists the organizations of the current user and their projects into the index. The listing
+// is made without holding the lock, and concurrent loads wait for the one in progress instead of
+// listing everything again.
+func (r *Resolver) load(ctx context.Context) (*resolverIndex, error) {
+	r.mu.Lock()
+	if l := r.loading; l != nil {
+		r.mu.Unlock()
+		select {
+		case <-l.done:
+			return l.index, l.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	l := &resolverLoad{done: make(chan struct{})}
+	r.loading = l
+	r.mu.Unlock()
+
+	l.index, l.err = r.list(ctx)
+
+	r.mu.Lock()
+	if l.err == nil {
+		r.index, r.loadedAt = l.index, r.now()
+	}
+	r.loading = nil
+	r.mu.Unlock()
+	close(l.done)
+	return l.index, l.err
+}
+
+// list lists the organizations of the current user and their projects.
+func (r *Resolver) list(ctx context.Context) (*resolverIndex, error) {
+	all := PageOptions{MaxItems: MaxItemsLimit}
+	orgs, _, err := ListPage[*bugsnagAPI.Organization](ctx, r.client, "user/organizations", nil, all)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	idx := &resolverIndex{organizations: orgs}
+	for _, org := range orgs {
+		projects, _, err := ListPage[*bugsnagAPI.Project](ctx, r.client, "organizations/"+url.PathEscape(org.ID)+"/projects", nil, all)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects of organization %s: %w", org.Slug, err)
+		}
+		idx.projects = append(idx.projects, projects...)
+	}
+	return idx, nil
+}
+
+// matchOrganizations returns the organizations matching ref, preferring slug matches over name matches.
+func (idx *resolverIndex) matchOrganizations(ref string) []resolverMatch {
+	var bySlug, byName []resolverMatch
+	for _, org := range idx.organizations {
+		switch {
+		case org.ID == ref:
+			return []resolverMatch{{org.ID, org.Name, org.Slug}}
+		case strings.EqualFold(org.Slug, ref):
+			bySlug = append(bySlug, resolverMatch{org.ID, org.Name, org.Slug})
+		case strings.EqualFold(org.Name, ref):
+			byName = append(byName, resolverMatch{org.ID, org.Name, org.Slug})
+		}
+	}
+	if len(bySlug) > 0 {
+		return bySlug
+	}
+	return byName
+}
+
+// matchProjects returns the projects matching projectRef, preferring slug matches over name matches.
+// When orgRef is set, only projects of matching organizations are considered.
+func (idx *resolverIndex) matchProjects(orgRef, projectRef string) []resolverMatch {
+	orgs := make(map[string]*bugsnagAPI.Organization, len(idx.organizations))
+	for _, org := range idx.organizations {
+		orgs[org.ID] = org
+	}
+
+	var bySlug, byName []resolverMatch
+	for _, project := range idx.projects {
+		org := orgs[project.OrganizationID]
+		if orgRef != "" && (org == nil || (org.ID != orgRef && !strings.EqualFold(org.Slug, orgRef) && !strings.EqualFold(org.Name, orgRef))) {
+			continue
+		}
+		slug := project.Slug
+		if org != nil {
+			slug = org.Slug + "/" + project.Slug
+		}
+
+		switch {
+		case project.ID == projectRef:
+			return []resolverMatch{{project.ID, project.Name, slug}}
+		case strings.EqualFold(project.Slug, projectRef):
+			bySlug = append(bySlug, resolverMatch{project.ID, project.Name, slug})
+		case strings.EqualFold(project.Name, projectRef):
+			byName = append(byName, resolverMatch{project.ID, project.Name, slug})
+		}
+	}
+	if len(bySlug) > 0 {
+		return bySlug
+	}
+	return byName
+}
+
+// pickMatch returns the ID of the only match, or an error listing the candidates when there is no
+// match or the reference is ambiguous.
+func pickMatch(kind, ref string, matches []resolverMatch) (string, error) {
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s matches %q", kind, ref)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		candidates := make([]string, len(matches))
+		for i, m := range matches {
+			candidates[i] = fmt.Sprintf("%s (%q, %s)", m.ID, m.Name, m.Slug)
+		}
+		return "", fmt.Errorf("%q matches %d %ss, use one of the IDs: %s", ref, len(matches), kind, strings.Join(candidates, "; "))
+	}
+}
@@ -0,0 +1,214 @@
+package bugsnag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+const (
+	acmeID      = "5f0a1b2c3d4e5f6a7b8c9d01"
+	globexID    = "5f0a1b2c3d4e5f6a7b8c9d02"
+	acmeWebID   = "6a0a1b2c3d4e5f6a7b8c9d01"
+	acmeAPIID   = "6a0a1b2c3d4e5f6a7b8c9d02"
+	globexWebID = "6a0a1b2c3d4e5f6a7b8c9d03"
+	// acmeGatewayID is a project whose name contains a slash.
+	acmeGatewayID = "6a0a1b2c3d4e5f6a7b8c9d04"
+)
+
+// setupResolverServer serves two organizations with a "Web" project each, and a "Web/API" project in Acme, and counts the organization listings.
+func setupResolverServer(t *testing.T) (*bugsnagAPI.Client, *int) {
+	t.Helper()
+	listings := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user/organizations", func(w http.ResponseWriter, r *http.Request) {
+		listings++
+		_ = json.NewEncoder(w).Encode([]*bugsnagAPI.Organization{
+			{ID: acmeID, Slug: "acme", Name: "Acme"},
+			{ID: globexID, Slug: "globex", Name: "Globex Corp"},
+		})
+	})
+	mux.HandleFunc("/organizations/"+acmeID+"/projects", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*bugsnagAPI.Project{
+			{ID: acmeWebID, OrganizationID: acmeID, Slug: "web", Name: "Web"},
+			{ID: acmeAPIID, OrganizationID: acmeID, Slug: "api-server", Name: "API"},
+			{ID: acmeGatewayID, OrganizationID: acmeID, Slug: "gateway", Name: "Web/API"},
+		})
+	})
+	mux.HandleFunc("/organizations/"+globexID+"/projects", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*bugsnagAPI.Project{
+			{ID: globexWebID, OrganizationID: globexID, Slug: "web", Name: "Web"},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return bugsnagAPI.NewClient("test-token", bugsnagAPI.WithBaseURL(server.URL)), &listings
+}
+
+func TestResolveProject(t *testing.T) {
+	client, _ := setupResolverServer(t)
+	resolver := NewResolver(client)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "ID", ref: acmeAPIID, want: acmeAPIID},
+		{name: "slug", ref: "api-server", want: acmeAPIID},
+		{name: "name is case-insensitive", ref: "api", want: acmeAPIID},
+		{name: "organization and project slugs", ref: "globex/web", want: globexWebID},
+		{name: "organization name and project name", ref: "globex corp/Web", want: globexWebID},
+		{name: "name with a slash", ref: "web/api", want: acmeGatewayID},
+		{name: "organization and name with a slash", ref: "acme/Web/API", want: acmeGatewayID},
+		{name: "dashboard URL", ref: "https://app.bugsnag.com/acme/web/errors/abc?event_id=def", want: acmeWebID},
+		{name: "API URL", ref: "https://api.bugsnag.com/projects/" + globexWebID + "/errors", want: globexWebID},
+		{name: "ambiguous slug lists candidates", ref: "web", wantErr: `"web" matches 2 projects, use one of the IDs: ` + acmeWebID + ` ("Web", acme/web); ` + globexWebID + ` ("Web", globex/web)`},
+		{name: "unknown project", ref: "mobile", wantErr: `no project matches "mobile"`},
+		{name: "dashboard URL without project", ref: "https://app.bugsnag.com/acme", wantErr: "does not refer to a project"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.ResolveProject(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveProject(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveProject(%q) error = %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveProject(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveOrganization(t *testing.T) {
+	client, _ := setupResolverServer(t)
+	resolver := NewResolver(client)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "ID", ref: globexID, want: globexID},
+		{name: "slug", ref: "ACME", want: acmeID},
+		{name: "name", ref: "globex corp", want: globexID},
+		{name: "dashboard URL", ref: "https://app.bugsnag.com/globex/web", want: globexID},
+		{name: "unknown organization", ref: "initech", wantErr: true},
+		{name: "empty", ref: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.ResolveOrganization(context.Background(), tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveOrganization(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveOrganization(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverCachesIndex(t *testing.T) {
+	client, listings := setupResolverServer(t)
+	resolver := NewResolver(client)
+	ctx := context.Background()
+
+	for _, ref := range []string{"acme/web", "api", "globex/web"} {
+		if _, err := resolver.ResolveProject(ctx, ref); err != nil {
+			t.Fatalf("ResolveProject(%q) error = %v", ref, err)
+		}
+	}
+	if *listings != 1 {
+		t.Errorf("organizations listed %d times, want 1 for matching lookups", *listings)
+	}
+
+	// A miss right after listing does not list again
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+	for range 3 {
+		if _, err := resolver.ResolveProject(ctx, "mobile"); err == nil {
+			t.Fatal("ResolveProject(\"mobile\") error = nil, want no match")
+		}
+	}
+	if *listings != 1 {
+		t.Errorf("organizations listed %d times, want no reload within %s", *listings, minReloadInterval)
+	}
+
+	now = now.Add(minReloadInterval)
+	for range 3 {
+		if _, err := resolver.ResolveProject(ctx, "mobile"); err == nil {
+			t.Fatal("ResolveProject(\"mobile\") error = nil, want no match")
+		}
+	}
+	if *listings != 2 {
+		t.Errorf("organizations listed %d times, want a single reload after a miss", *listings)
+	}
+
+	resolver.Invalidate()
+	if _, err := resolver.ResolveOrganization(ctx, "acme"); err != nil {
+		t.Fatalf("ResolveOrganization() error = %v", err)
+	}
+	if *listings != 3 {
+		t.Errorf("organizations listed %d times, want a reload after Invalidate", *listings)
+	}
+}
+
+func TestResolverSharesLoads(t *testing.T) {
+	client, listings := setupResolverServer(t)
+	resolver := NewResolver(client)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := resolver.ResolveProject(context.Background(), "acme/web"); err != nil {
+				t.Errorf("ResolveProject() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if *listings != 1 {
+		t.Errorf("organizations listed %d times, want concurrent lookups to share one listing", *listings)
+	}
+}
+
+func TestResolverListings(t *testing.T) {
+	client, listings := setupResolverServer(t)
+	resolver := NewResolver(client)
+	ctx := context.Background()
+
+	orgs, err := resolver.Organizations(ctx)
+	if err != nil || len(orgs) != 2 {
+		t.Fatalf("Organizations() = %d organizations, %v, want 2", len(orgs), err)
+	}
+	projects, err := resolver.Projects(ctx)
+	if err != nil || len(projects) != 4 {
+		t.Fatalf("Projects() = %d projects, %v, want 4", len(projects), err)
+	}
+	if _, err := resolver.ResolveProject(ctx, "acme/api-server"); err != nil {
+		t.Fatalf("ResolveProject() error = %v", err)
+	}
+	if *listings != 1 {
+		t.Errorf("organizations listed %d times, want the listing shared with lookups", *listings)
+	}
+}
@@ -0,0 +1,213 @@
+package bugsnag
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitHeader          = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	retryAfterHeader         = "Retry-After"
+)
+
+// RetryOptions configures the retries of RetryTransport.
+type RetryOptions struct {
+	// MaxRetries is the number of times a request is retried. Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry when the API does not ask for one, doubled for each retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, including the ones asked for by the API.
+	MaxDelay time.Duration
+}
+
+// RateLimitError is returned when the Bugsnag API is still rate limiting requests once the retries are used up,
+// or when waiting for the rate limit to reset would exceed the deadline of the request.
+type RateLimitError struct {
+	// RetryAfter is how long the API asked to wait before retrying, if it did.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("Bugsnag API rate limit exceeded, retry after %s", e.RetryAfter)
+	}
+	return "Bugsnag API rate limit exceeded"
+}
+
+// RetryTransport returns an http.RoundTripper retrying the idempotent requests that were rate limited or failed
+// with a temporary error. It waits as long as the Retry-After header asks, or with jittered exponential backoff,
+// and gives up early rather than waiting past the deadline of the request context.
+func RetryTransport(next http.RoundTripper, opts RetryOptions) http.RoundTripper {
+	return retryTransport{next: next, opts: opts}
+}
+
+type retryTransport struct {
+	next http.RoundTripper
+	opts RetryOptions
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil {
+			recordRateLimit(ctx, resp.Header)
+		}
+		if !retryable(resp, err) {
+			return resp, err
+		}
+
+		retryAfter, asked := parseRetryAfter(resp)
+		delay := t.backoff(attempt, retryAfter, asked)
+		deadline, hasDeadline := ctx.Deadline()
+		if !idempotent || attempt >= t.opts.MaxRetries || ctx.Err() != nil || (hasDeadline && time.Now().Add(delay).After(deadline)) {
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				drain(resp)
+				return nil, &RateLimitError{RetryAfter: retryAfter}
+			}
+			return resp, err
+		}
+
+		slog.Debug("Retrying Bugsnag API request",
+			slog.String("path", req.URL.Path), slog.Int("attempt", attempt+1), slog.Duration("delay", delay))
+		if resp != nil {
+			drain(resp)
+		}
+		recordRetry(ctx)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before a retry: the one the API asked for, or a random delay up to the
+// exponentially growing backoff (full jitter), capped at MaxDelay.
+func (t retryTransport) backoff(attempt int, retryAfter time.Duration, asked bool) time.Duration {
+	if asked {
+		return min(retryAfter, t.opts.MaxDelay)
+	}
+	backoff := min(t.opts.BaseDelay<<attempt, t.opts.MaxDelay)
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff) + 1
+}
+
+// retryable reports whether a request is worth retrying: it was rate limited, the API was temporarily
+// unavailable, or the connection failed.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter returns the delay asked for by the Retry-After header of resp, in seconds or as an HTTP date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get(retryAfterHeader)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// drain discards the rest of the body of a response that will not be returned, so the connection can be reused.
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
+
+// RateLimit is the request quota reported by the Bugsnag API.
+type RateLimit struct {
+	// Limit is the number of requests allowed per rate limit window.
+	Limit int `json:"limit"`
+	// Remaining is the number of requests left in the current window.
+	Remaining int `json:"remaining"`
+	// Retries is the number of requests that were retried after being rate limited or failing.
+	Retries int `json:"retries,omitempty"`
+}
+
+// RateLimitRecorder records the latest quota reported by the Bugsnag API for the requests made with its context.
+type RateLimitRecorder struct {
+	mu        sync.Mutex
+	rateLimit *RateLimit
+	retries   int
+}
+
+type rateLimitRecorderKey struct{}
+
+// WithRateLimitRecorder returns a copy of ctx whose Bugsnag API requests record their quota in the returned recorder.
+func WithRateLimitRecorder(ctx context.Context) (context.Context, *RateLimitRecorder) {
+	r := &RateLimitRecorder{}
+	return context.WithValue(ctx, rateLimitRecorderKey{}, r), r
+}
+
+// RateLimit returns the latest quota reported, or nil if no request reported one or was retried.
+func (r *RateLimitRecorder) RateLimit() *RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rateLimit == nil && r.retries == 0 {
+		return nil
+	}
+	rateLimit := RateLimit{Retries: r.retries}
+	if r.rateLimit != nil {
+		rateLimit.Limit = r.rateLimit.Limit
+		rateLimit.Remaining = r.rateLimit.Remaining
+	}
+	return &rateLimit
+}
+
+// recordRateLimit records the quota from the X-RateLimit headers of a response in the recorder of ctx, if any.
+func recordRateLimit(ctx context.Context, header http.Header) {
+	r, ok := ctx.Value(rateLimitRecorderKey{}).(*RateLimitRecorder)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(header.Get(rateLimitHeader))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get(rateLimitRemainingHeader))
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	r.rateLimit = &RateLimit{Limit: limit, Remaining: remaining}
+	r.mu.Unlock()
+}
+
+// recordRetry counts a retry in the recorder of ctx, if any.
+func recordRetry(ctx context.Context) {
+	if r, ok := ctx.Value(rateLimitRecorderKey{}).(*RateLimitRecorder); ok {
+		r.mu.Lock()
+		r.retries++
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,147 @@
+package bugsnag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// setupFlakyServer responds with the given statuses in turn, then 200, reporting a shrinking quota.
+func setupFlakyServer(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		w.Header().Set(rateLimitHeader, "10")
+		w.Header().Set(rateLimitRemainingHeader, strconv.Itoa(10-n))
+		if n <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set(retryAfterHeader, retryAfter)
+			}
+			w.WriteHeader(statuses[n-1])
+			w.Write([]byte(`{"errors":["try again"]}`))
+			return
+		}
+		w.Write([]byte(`{"id":"abc"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newRetryingClient(server *httptest.Server, opts RetryOptions) *bugsnagAPI.Client {
+	return bugsnagAPI.NewClient("token",
+		bugsnagAPI.WithBaseURL(server.URL),
+		bugsnagAPI.WithHTTPClient(&http.Client{Transport: RetryTransport(http.DefaultTransport, opts)}),
+	)
+}
+
+func TestRetryTransport(t *testing.T) {
+	fast := RetryOptions{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		retryAfter   string
+		statuses     []int
+		opts         RetryOptions
+		timeout      time.Duration
+		wantRequests int32
+		wantErr      bool
+		wantRateErr  bool
+	}{
+		{name: "success", opts: fast, wantRequests: 1},
+		{name: "rate limited then succeeds", retryAfter: "0", statuses: []int{429, 429}, opts: fast, wantRequests: 3},
+		{name: "unavailable then succeeds", statuses: []int{503, 502}, opts: fast, wantRequests: 3},
+		{name: "client errors are not retried", statuses: []int{404}, opts: fast, wantRequests: 1, wantErr: true},
+		{name: "gives up after the retries", retryAfter: "0", statuses: []int{429, 429, 429, 429}, opts: fast, wantRequests: 4, wantErr: true, wantRateErr: true},
+		{name: "retries disabled", statuses: []int{429}, opts: RetryOptions{}, wantRequests: 1, wantErr: true, wantRateErr: true},
+		{
+			name: "does not wait past the deadline", retryAfter: "30", statuses: []int{429},
+			opts: RetryOptions{MaxRetries: 3, MaxDelay: time.Minute}, timeout: time.Second, wantRequests: 1, wantErr: true, wantRateErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := setupFlakyServer(t, tt.retryAfter, tt.statuses...)
+			client := newRetryingClient(server, tt.opts)
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			var v map[string]any
+			_, err := List(ctx, client, "projects/abc", nil, &v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			var rateErr *RateLimitError
+			if errors.As(err, &rateErr) != tt.wantRateErr {
+				t.Errorf("List() error = %v, want a RateLimitError: %t", err, tt.wantRateErr)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if elapsed := time.Since(start); tt.timeout > 0 && elapsed > tt.timeout/2 {
+				t.Errorf("List() took %s, want it to give up without waiting", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryTransportNonIdempotent(t *testing.T) {
+	server, requests := setupFlakyServer(t, "0", 429)
+	client := newRetryingClient(server, RetryOptions{MaxRetries: 3})
+
+	req, err := client.NewRequest(http.MethodPatch, "projects/abc/errors/def", map[string]string{"operation": "fix"})
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if _, err := client.Do(context.Background(), req, nil); err == nil {
+		t.Error("Do() error = nil, want the rate limit error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want PATCH not to be retried", got)
+	}
+}
+
+func TestRetryTransportHonorsRetryAfter(t *testing.T) {
+	server, _ := setupFlakyServer(t, "1", 429)
+	client := newRetryingClient(server, RetryOptions{MaxRetries: 1, MaxDelay: time.Minute})
+
+	start := time.Now()
+	var v map[string]any
+	if _, err := List(context.Background(), client, "projects/abc", nil, &v); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("List() took %s, want it to wait for the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestRateLimitRecorder(t *testing.T) {
+	server, _ := setupFlakyServer(t, "0", 429)
+	client := newRetryingClient(server, RetryOptions{MaxRetries: 1})
+
+	ctx, recorder := WithRateLimitRecorder(context.Background())
+	if got := recorder.RateLimit(); got != nil {
+		t.Errorf("RateLimit() before any request = %+v, want nil", got)
+	}
+	var v map[string]any
+	if _, err := List(ctx, client, "projects/abc", nil, &v); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := RateLimit{Limit: 10, Remaining: 8, Retries: 1}
+	if got := recorder.RateLimit(); got == nil || *got != want {
+		t.Errorf("RateLimit() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package bugsnag
+
+import (
+	"fmt"
+	"sort"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// SavedSearch is a named set of error filters saved in the dashboard, which the API client does not cover.
+// API docs: https://bugsnagapiv2.docs.apiary.io/#reference/projects/saved-searches
+type SavedSearch struct {
+	ID        string                         `json:"id"`
+	ProjectID string                         `json:"project_id"`
+	Name      string                         `json:"name"`
+	Filters   map[string][]SavedSearchFilter `json:"filters"`
+	Sort      string                         `json:"sort,omitempty"`
+	Shared    bool                           `json:"shared"`
+}
+
+// SavedSearchFilter is one value a field of a saved search is filtered on.
+type SavedSearchFilter struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// FilterList returns the filters of the saved search, sorted by field.
+func (s *SavedSearch) FilterList() []bugsnagAPI.Filter {
+	fields := make([]string, 0, len(s.Filters))
+	for field := range s.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var filters []bugsnagAPI.Filter
+	for _, field := range fields {
+		for _, f := range s.Filters[field] {
+			filterType := f.Type
+			if filterType == "" {
+				filterType = FilterTypeEqual
+			}
+			filters = append(filters, bugsnagAPI.Filter{Key: field, Type: filterType, Value: fmt.Sprint(f.Value)})
+		}
+	}
+	return filters
+}
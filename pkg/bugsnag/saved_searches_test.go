@@ -0,0 +1,26 @@
+package bugsnag
+
+import (
+	"reflect"
+	"testing"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestSavedSearchFilterList(t *testing.T) {
+	search := &SavedSearch{Filters: map[string][]SavedSearchFilter{
+		"event.since":  {{Type: "eq", Value: "7d"}},
+		"error.status": {{Value: "open"}, {Type: "eq", Value: "snoozed"}},
+		"app.is_beta":  {{Type: "ne", Value: true}},
+	}}
+
+	want := []bugsnagAPI.Filter{
+		{Key: "app.is_beta", Type: "ne", Value: "true"},
+		{Key: "error.status", Type: "eq", Value: "open"},
+		{Key: "error.status", Type: "eq", Value: "snoozed"},
+		{Key: "event.since", Type: "eq", Value: "7d"},
+	}
+	if got := search.FilterList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterList() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,240 @@
+package bugsnag
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// maxRecursionPeriod is the largest block of frames detected as repeating recursion.
+const maxRecursionPeriod = 10
+
+// libraryPathMarkers are path segments of dependencies, used to tell library frames from project frames
+// when Bugsnag did not mark any frame as in the project.
+var libraryPathMarkers = []string{"node_modules/", "vendor/", "site-packages/", "dist-packages/", "/pkg/mod/", ".cargo/registry/", "/usr/lib/", "/usr/local/lib/"}
+
+// libraryMethodPrefixes are prefixes of the methods of runtime libraries, used like libraryPathMarkers.
+var libraryMethodPrefixes = []string{"java.", "javax.", "jdk.", "sun.", "kotlin.", "kotlinx.", "android.", "androidx.", "dalvik.", "com.android.", "runtime.", "System.", "Microsoft."}
+
+// TraceEntryKind is the kind of an entry of a processed stack trace.
+type TraceEntryKind int
+
+const (
+	// TraceFrame is a single frame.
+	TraceFrame TraceEntryKind = iota
+	// TraceLibraryFrames stands for consecutive library frames collapsed into one entry.
+	TraceLibraryFrames
+	// TraceRecursion says the block of frames before it repeated, and was removed.
+	TraceRecursion
+)
+
+// TraceEntry is an entry of a processed stack trace.
+type TraceEntry struct {
+	Kind TraceEntryKind
+	// Frame is the frame of a TraceFrame entry, with InProject set.
+	Frame bugsnagAPI.Stacktrace
+	// Frames is the number of library frames collapsed, or the number of frames in the repeated block.
+	Frames int
+	// Modules are the distinct modules of the collapsed library frames, in order.
+	Modules []string
+	// Repeats is how many more times the repeated block appeared.
+	Repeats int
+}
+
+// ProcessStacktrace normalizes the frames of a stack trace: it marks the frames in the project, using
+// heuristics when Bugsnag marked none, removes repeated blocks of recursive frames, and collapses consecutive
+// library frames into one entry. The top frame is always kept, as it is where the error was raised.
+func ProcessStacktrace(frames []bugsnagAPI.Stacktrace) []TraceEntry {
+	frames = slices.Clone(frames)
+	if !slices.ContainsFunc(frames, func(f bugsnagAPI.Stacktrace) bool { return f.InProject }) {
+		for i := range frames {
+			frames[i].InProject = !isLibraryFrame(frames[i])
+		}
+	}
+
+	var entries []TraceEntry
+	// fixed is the number of leading entries never merged into collapsed library frames
+	fixed := 0
+	add := func(frame bugsnagAPI.Stacktrace, keep bool) {
+		last := len(entries) - 1
+		switch {
+		case keep || frame.InProject:
+			entries = append(entries, TraceEntry{Kind: TraceFrame, Frame: frame})
+			if keep {
+				fixed = len(entries)
+			}
+		case last >= fixed && entries[last].Kind == TraceLibraryFrames:
+			entries[last].Frames++
+			if module := frameModule(frame); !slices.Contains(entries[last].Modules, module) {
+				entries[last].Modules = append(entries[last].Modules, module)
+			}
+		case last >= fixed && !entries[last].Frame.InProject:
+			modules := []string{frameModule(entries[last].Frame)}
+			if module := frameModule(frame); module != modules[0] {
+				modules = append(modules, module)
+			}
+			entries[last] = TraceEntry{Kind: TraceLibraryFrames, Frames: 2, Modules: modules}
+		default:
+			entries = append(entries, TraceEntry{Kind: TraceFrame, Frame: frame})
+		}
+	}
+
+	for i := 0; i < len(frames); {
+		period, repeats := findRecursion(frames[i:])
+		if repeats == 0 {
+			add(frames[i], i == 0)
+			i++
+			continue
+		}
+		// Keep the repeated block whole, so that the recursion entry describes the frames before it
+		for _, frame := range frames[i : i+period] {
+			add(frame, true)
+		}
+		entries = append(entries, TraceEntry{Kind: TraceRecursion, Frames: period, Repeats: repeats})
+		fixed = len(entries)
+		i += (repeats + 1) * period
+	}
+	return entries
+}
+
+// findRecursion finds the block of frames at the start of frames that repeats right after itself the most,
+// returning its length and the number of extra repeats, or 0, 0.
+func findRecursion(frames []bugsnagAPI.Stacktrace) (period, repeats int) {
+	best := 0
+	for p := 1; p <= maxRecursionPeriod && 2*p <= len(frames); p++ {
+		r := 0
+		for (r+2)*p <= len(frames) && sameFrames(frames[:p], frames[(r+1)*p:(r+2)*p]) {
+			r++
+		}
+		if r > 0 && r*p > best {
+			best, period, repeats = r*p, p, r
+		}
+	}
+	return period, repeats
+}
+
+func sameFrames(a, b []bugsnagAPI.Stacktrace) bool {
+	return slices.EqualFunc(a, b, func(x, y bugsnagAPI.Stacktrace) bool {
+		return x.Method == y.Method && x.File == y.File && x.LineNumber == y.LineNumber
+	})
+}
+
+// isLibraryFrame guesses whether a frame is in a dependency or runtime library from its file and method.
+func isLibraryFrame(frame bugsnagAPI.Stacktrace) bool {
+	for _, marker := range libraryPathMarkers {
+		if strings.Contains(frame.File, marker) {
+			return true
+		}
+	}
+	for _, prefix := range libraryMethodPrefixes {
+		if strings.HasPrefix(frame.Method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// frameModule returns the module of a library frame: the dependency its file is in, the directory of
+// its file, or the package of its method.
+func frameModule(frame bugsnagAPI.Stacktrace) string {
+	if frame.File == "" {
+		if i := strings.LastIndexByte(frame.Method, '.'); i > 0 {
+			return frame.Method[:i]
+		}
+		return frame.Method
+	}
+	for _, marker := range libraryPathMarkers {
+		if _, rest, ok := strings.Cut(frame.File, marker); ok {
+			segments := strings.Split(rest, "/")
+			// Go modules are identified by the path up to their version, e.g. github.com/org/repo@v1.2.3
+			for i, segment := range segments {
+				if strings.Contains(segment, "@") {
+					return strings.Join(segments[:i+1], "/")
+				}
+			}
+			return segments[0]
+		}
+	}
+	return path.Dir(frame.File)
+}
+
+// TraceFormatOptions configures FormatStacktrace.
+type TraceFormatOptions struct {
+	// MaxEntries is the number of entries rendered. Zero renders them all.
+	MaxEntries int
+	// AllSnippets renders the code of every project frame that has it, instead of only the top one.
+	AllSnippets bool
+}
+
+// FormatStacktrace renders a processed stack trace as a Markdown list, with the project frames in bold
+// and the code around the top project frame, or all of them with opts.AllSnippets, when Bugsnag provides it.
+func FormatStacktrace(entries []TraceEntry, opts TraceFormatOptions) string {
+	var b strings.Builder
+	shown := entries
+	if opts.MaxEntries > 0 && len(shown) > opts.MaxEntries {
+		shown = shown[:opts.MaxEntries]
+	}
+
+	snippet := true
+	for _, entry := range shown {
+		switch entry.Kind {
+		case TraceFrame:
+			frame := entry.Frame
+			location := fmt.Sprintf("%s:%d", orUnknown(frame.File), frame.LineNumber)
+			if frame.InProject {
+				fmt.Fprintf(&b, "- **%s** `%s`\n", location, orUnknown(frame.Method))
+			} else {
+				fmt.Fprintf(&b, "- %s `%s`\n", location, orUnknown(frame.Method))
+			}
+			if frame.InProject && len(frame.Code) > 0 && snippet {
+				writeSnippet(&b, frame)
+				snippet = opts.AllSnippets
+			}
+		case TraceLibraryFrames:
+			modules := entry.Modules
+			if len(modules) > 3 {
+				modules = append(modules[:3:3], "…")
+			}
+			fmt.Fprintf(&b, "- _%d library frames (%s)_\n", entry.Frames, strings.Join(modules, ", "))
+		case TraceRecursion:
+			fmt.Fprintf(&b, "- _the previous %d frame(s) repeated %d more time(s)_\n", entry.Frames, entry.Repeats)
+		}
+	}
+	if hidden := len(entries) - len(shown); hidden > 0 {
+		fmt.Fprintf(&b, "- _%d more entries_\n", hidden)
+	}
+	return b.String()
+}
+
+// writeSnippet writes the code around a frame, pointing at its line.
+func writeSnippet(b *strings.Builder, frame bugsnagAPI.Stacktrace) {
+	lines := slices.Collect(maps.Keys(frame.Code))
+	slices.SortFunc(lines, func(x, y string) int { return cmp.Compare(lineNumber(x), lineNumber(y)) })
+	b.WriteString("  ```\n")
+	for _, line := range lines {
+		marker := " "
+		if lineNumber(line) == frame.LineNumber {
+			marker = ">"
+		}
+		fmt.Fprintf(b, "  %s %4s | %s\n", marker, line, frame.Code[line])
+	}
+	b.WriteString("  ```\n")
+}
+
+func lineNumber(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "?"
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+package bugsnag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// frame returns a stack frame of method in file, in the project if inProject.
+func frame(method, file string, inProject bool) bugsnagAPI.Stacktrace {
+	return bugsnagAPI.Stacktrace{Method: method, File: file, LineNumber: 1, InProject: inProject}
+}
+
+// describeTrace describes processed entries compactly: project frames with a leading "*", collapsed library
+// frames as "N library frames (modules)" and recursion as "N frames repeated R times".
+func describeTrace(entries []TraceEntry) []string {
+	var got []string
+	for _, entry := range entries {
+		switch entry.Kind {
+		case TraceFrame:
+			if entry.Frame.InProject {
+				got = append(got, "*"+entry.Frame.Method)
+			} else {
+				got = append(got, entry.Frame.Method)
+			}
+		case TraceLibraryFrames:
+			got = append(got, fmt.Sprintf("%d library frames (%s)", entry.Frames, strings.Join(entry.Modules, ", ")))
+		case TraceRecursion:
+			got = append(got, fmt.Sprintf("%d frames repeated %d times", entry.Frames, entry.Repeats))
+		}
+	}
+	return got
+}
+
+func TestProcessStacktrace(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []bugsnagAPI.Stacktrace
+		want   []string
+	}{
+		{
+			name: "collapses consecutive library frames",
+			frames: []bugsnagAPI.Stacktrace{
+				frame("main.load", "cmd/api/store.go", true),
+				frame("database/sql.(*DB).Query", "database/sql/sql.go", false),
+				frame("database/sql.(*DB).query", "database/sql/sql.go", false),
+				frame("main.handleUsers", "cmd/api/users.go", true),
+				frame("net/http.HandlerFunc.ServeHTTP", "net/http/server.go", false),
+				frame("net/http.(*ServeMux).ServeHTTP", "net/http/server.go", false),
+				frame("net/http.serverHandler.ServeHTTP", "net/http/server.go", false),
+				frame("runtime.goexit", "runtime/asm_amd64.s", false),
+			},
+			want: []string{"*main.load", "2 library frames (database/sql)", "*main.handleUsers", "4 library frames (net/http, runtime)"},
+		},
+		{
+			name: "keeps single library frames and the top frame",
+			frames: []bugsnagAPI.Stacktrace{
+				frame("runtime.panicmem", "runtime/panic.go", false),
+				frame("runtime.sigpanic", "runtime/signal_unix.go", false),
+				frame("main.load", "cmd/api/store.go", true),
+				frame("sort.Slice", "sort/slice.go", false),
+				frame("main.main", "cmd/api/main.go", true),
+			},
+			want: []string{"runtime.panicmem", "runtime.sigpanic", "*main.load", "sort.Slice", "*main.main"},
+		},
+		{
+			name: "dedupes recursion",
+			frames: []bugsnagAPI.Stacktrace{
+				frame("main.walk", "cmd/api/tree.go", true),
+				frame("main.visit", "cmd/api/tree.go", true),
+				frame("main.walk", "cmd/api/tree.go", true),
+				frame("main.visit", "cmd/api/tree.go", true),
+				frame("main.walk", "cmd/api/tree.go", true),
+				frame("main.visit", "cmd/api/tree.go", true),
+				frame("main.main", "cmd/api/main.go", true),
+			},
+			want: []string{"*main.walk", "*main.visit", "2 frames repeated 2 times", "*main.main"},
+		},
+		{
+			name: "does not collapse repeated library frames",
+			frames: []bugsnagAPI.Stacktrace{
+				frame("main.main", "cmd/api/main.go", true),
+				frame("lodash.each", "node_modules/lodash/each.js", false),
+				frame("lodash.each", "node_modules/lodash/each.js", false),
+				frame("lodash.each", "node_modules/lodash/each.js", false),
+				frame("express.handle", "node_modules/express/lib/router.js", false),
+			},
+			want: []string{"*main.main", "lodash.each", "1 frames repeated 2 times", "express.handle"},
+		},
+		{
+			name: "marks project frames by path when Bugsnag marked none",
+			frames: []bugsnagAPI.Stacktrace{
+				frame("render", "app/views/user.js", false),
+				frame("next", "node_modules/express/lib/router/index.js", false),
+				frame("handle", "node_modules/express/lib/router/layer.js", false),
+				frame("json", "node_modules/body-parser/lib/types/json.js", false),
+				frame("query", "/go/pkg/mod/github.com/lib/pq@v1.10.9/conn.go", false),
+				frame("com.acme.Main.run", "Main.java", false),
+				frame("java.lang.Thread.run", "Thread.java", false),
+			},
+			want: []string{"*render", "4 library frames (express, body-parser, github.com/lib/pq@v1.10.9)", "*com.acme.Main.run", "java.lang.Thread.run"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeTrace(ProcessStacktrace(tt.frames)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProcessStacktrace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStacktrace(t *testing.T) {
+	frames := []bugsnagAPI.Stacktrace{
+		frame("runtime.panicmem", "runtime/panic.go", false),
+		{Method: "main.load", File: "cmd/api/store.go", LineNumber: 7, InProject: true, Code: map[string]string{"6": "row := db.QueryRow(q)", "7": "return row.Scan(&u)", "10": "}"}},
+		{Method: "main.handleUsers", File: "cmd/api/users.go", LineNumber: 42, InProject: true, Code: map[string]string{"42": "return user.Name"}},
+		frame("net/http.HandlerFunc.ServeHTTP", "net/http/server.go", false),
+		frame("net/http.serverHandler.ServeHTTP", "net/http/server.go", false),
+		frame("main.main", "", true),
+	}
+	entries := ProcessStacktrace(frames)
+
+	t.Run("top snippet", func(t *testing.T) {
+		want := "- runtime/panic.go:1 `runtime.panicmem`\n" +
+			"- **cmd/api/store.go:7** `main.load`\n" +
+			"  ```\n" +
+			"       6 | row := db.QueryRow(q)\n" +
+			"  >    7 | return row.Scan(&u)\n" +
+			"      10 | }\n" +
+			"  ```\n" +
+			"- **cmd/api/users.go:42** `main.handleUsers`\n" +
+			"- _2 library frames (net/http)_\n" +
+			"- **?:1** `main.main`\n"
+		if got := FormatStacktrace(entries, TraceFormatOptions{}); got != want {
+			t.Errorf("FormatStacktrace() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("all snippets", func(t *testing.T) {
+		got := FormatStacktrace(entries, TraceFormatOptions{AllSnippets: true})
+		if !strings.Contains(got, "  >   42 | return user.Name\n") {
+			t.Errorf("FormatStacktrace() does not show the code of every project frame:\n%s", got)
+		}
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		got := FormatStacktrace(entries, TraceFormatOptions{MaxEntries: 2})
+		if !strings.HasSuffix(got, "- _3 more entries_\n") || strings.Contains(got, "main.handleUsers") {
+			t.Errorf("FormatStacktrace() does not stop at 2 entries:\n%s", got)
+		}
+	})
+}
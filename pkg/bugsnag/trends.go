@@ -0,0 +1,12 @@
+package bugsnag
+
+import "time"
+
+// TrendBucket is the number of events received in a period of time, as returned by the
+// Bugsnag trend endpoints, which the API client does not cover.
+// API docs: https://bugsnagapiv2.docs.apiary.io/#reference/errors/trends
+type TrendBucket struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	EventsCount int       `json:"events_count"`
+}
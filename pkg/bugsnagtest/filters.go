@@ -0,0 +1,121 @@
+package bugsnagtest
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// filter is a condition on a field, in the Bugsnag filters query syntax.
+type filter struct {
+	Type  string
+	Value string
+}
+
+// filters are the conditions of a request by field.
+type filters map[string][]filter
+
+// parseFilters parses the filters[field][][type] and filters[field][][value] query parameters.
+func parseFilters(query url.Values) filters {
+	f := filters{}
+	for key, values := range query {
+		field, ok := strings.CutPrefix(key, "filters[")
+		if !ok {
+			continue
+		}
+		if field, ok = strings.CutSuffix(field, "][][value]"); !ok {
+			continue
+		}
+		types := query["filters["+field+"][][type]"]
+		for i, value := range values {
+			filterType := "eq"
+			if i < len(types) {
+				filterType = types[i]
+			}
+			f[field] = append(f[field], filter{Type: filterType, Value: value})
+		}
+	}
+	return f
+}
+
+// matchError reports whether an error matches the filters on its status, class, severity and release stages,
+// and was seen between event.since and event.before.
+func (f filters) matchError(e *bugsnagAPI.Error) bool {
+	fields := map[string][]string{
+		"error.status":      {e.Status},
+		"event.class":       {e.ErrorClass},
+		"event.severity":    {e.Severity},
+		"app.release_stage": e.ReleaseStages,
+	}
+	return f.matchFields(fields) && f.matchTimes(e.FirstSeen, e.LastSeen)
+}
+
+// matchEvent reports whether an event matches the filters on its class, severity, app, user and device,
+// and was received between event.since and event.before.
+func (f filters) matchEvent(event *bugsnagAPI.Event) bool {
+	return f.matchFields(eventFields(event)) && f.matchTimes(event.ReceivedAt, event.ReceivedAt)
+}
+
+// eventFields returns the values of the event fields known to the fake API, for filters and pivots.
+func eventFields(event *bugsnagAPI.Event) map[string][]string {
+	class := ""
+	if len(event.Exceptions) > 0 {
+		class = event.Exceptions[0].ErrorClass
+	}
+	return map[string][]string{
+		"error.id":           {event.ErrorID},
+		"event.class":        {class},
+		"event.severity":     {event.Severity},
+		"app.release_stage":  {event.App.ReleaseStage},
+		"app.version":        {event.App.Version},
+		"user.id":            {event.User.ID},
+		"user.email":         {event.User.Email},
+		"device.osName":      {event.Device.OsName},
+		"device.browserName": {event.Device.BrowserName},
+	}
+}
+
+// matchFields reports whether the values of the known fields match their filters: at least one of the
+// eq filters of a field, and none of its ne filters. Filters on other fields are ignored.
+func (f filters) matchFields(fields map[string][]string) bool {
+	for field, conditions := range f {
+		values, ok := fields[field]
+		if !ok {
+			continue
+		}
+		matched, hasEqual := false, false
+		for _, c := range conditions {
+			switch c.Type {
+			case "ne":
+				if slices.Contains(values, c.Value) {
+					return false
+				}
+			default:
+				hasEqual = true
+				matched = matched || slices.Contains(values, c.Value)
+			}
+		}
+		if hasEqual && !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTimes reports whether the period from first to last overlaps the event.since and event.before filters.
+func (f filters) matchTimes(first, last time.Time) bool {
+	for _, c := range f["event.since"] {
+		if since, err := time.Parse(time.RFC3339, c.Value); err == nil && last.Before(since) {
+			return false
+		}
+	}
+	for _, c := range f["event.before"] {
+		if before, err := time.Parse(time.RFC3339, c.Value); err == nil && !first.Before(before) {
+			return false
+		}
+	}
+	return true
+}
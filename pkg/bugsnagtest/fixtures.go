@@ -0,0 +1,165 @@
+package bugsnagtest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// IDs of the entities of the default fixtures.
+const (
+	AcmeOrgID   = "5f0a1b2c3d4e5f6a7b8c9d01"
+	GlobexOrgID = "5f0a1b2c3d4e5f6a7b8c9d02"
+
+	WebProjectID     = "6a0a1b2c3d4e5f6a7b8c9d01"
+	APIProjectID     = "6a0a1b2c3d4e5f6a7b8c9d02"
+	AndroidProjectID = "6a0a1b2c3d4e5f6a7b8c9d03"
+
+	// NilPointerErrorID is an open error of the API project with three events, the most recent being NilPointerEventID.
+	NilPointerErrorID = "7e0a1b2c3d4e5f6a7b8c9d01"
+	NilPointerEventID = "8e0a1b2c3d4e5f6a7b8c9d01"
+	// DeadlineErrorID is a fixed error of the API project, whose event has a recursive stack trace.
+	DeadlineErrorID = "7e0a1b2c3d4e5f6a7b8c9d03"
+
+	// APIStagingReleaseID and APIProductionReleaseID are the releases of version 1.4.1 of the API project,
+	// which also has a production release of version 1.4.0.
+	APIStagingReleaseID    = "9a0a1b2c3d4e5f6a7b8c9d02"
+	APIProductionReleaseID = "9a0a1b2c3d4e5f6a7b8c9d03"
+)
+
+//go:embed fixtures.json
+var defaultFixtures []byte
+
+// Fixtures are the entities served by a fake Bugsnag API. Events belong to the project of their error, and
+// releases are keyed by the ID of their project.
+type Fixtures struct {
+	Organizations []*bugsnagAPI.Organization    `json:"organizations"`
+	Projects      []*bugsnagAPI.Project         `json:"projects"`
+	Errors        []*bugsnagAPI.Error           `json:"errors"`
+	Events        []*bugsnagAPI.Event           `json:"events"`
+	Releases      map[string][]*bugsnag.Release `json:"releases,omitempty"`
+}
+
+// DefaultFixtures returns a fresh copy of the built-in fixtures: two organizations with three projects
+// in Go, JavaScript and Android, a few errors in each, their events with stack traces, breadcrumbs and metadata,
+// and the releases of the Go project.
+func DefaultFixtures() *Fixtures {
+	fixtures, err := parseFixtures(defaultFixtures)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in fixtures: %v", err))
+	}
+	return fixtures
+}
+
+// LoadFixtures reads fixtures from a JSON file in the format of DefaultFixtures.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := parseFixtures(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixtures in %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+func parseFixtures(data []byte) (*Fixtures, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var fixtures Fixtures
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, err
+	}
+	return &fixtures, nil
+}
+
+// Rebase moves every timestamp of the fixtures by the same amount, so that the most recent event was
+// received at now. It keeps time based filters such as since=24h meaningful when serving fixed fixtures.
+func (f *Fixtures) Rebase(now time.Time) {
+	var latest time.Time
+	for _, event := range f.Events {
+		if event.ReceivedAt.After(latest) {
+			latest = event.ReceivedAt
+		}
+	}
+	if latest.IsZero() {
+		return
+	}
+	shift := now.Sub(latest)
+	move := func(t *time.Time) {
+		if !t.IsZero() {
+			*t = t.Add(shift)
+		}
+	}
+
+	for _, e := range f.Errors {
+		move(&e.FirstSeen)
+		move(&e.LastSeen)
+		move(&e.FirstSeenUnfiltered)
+	}
+	for _, event := range f.Events {
+		move(&event.ReceivedAt)
+		for i := range event.Breadcrumbs {
+			move(&event.Breadcrumbs[i].Timestamp)
+		}
+	}
+	for _, releases := range f.Releases {
+		for _, release := range releases {
+			move(&release.ReleaseTime)
+		}
+	}
+}
+
+// project returns the project with the given ID, or nil.
+func (f *Fixtures) project(id string) *bugsnagAPI.Project {
+	for _, project := range f.Projects {
+		if project.ID == id {
+			return project
+		}
+	}
+	return nil
+}
+
+// error returns the error of a project with the given ID, or nil.
+func (f *Fixtures) error(projectID, id string) *bugsnagAPI.Error {
+	for _, e := range f.Errors {
+		if e.ProjectID == projectID && e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// projectEvents returns the events of the errors of a project.
+func (f *Fixtures) projectEvents(projectID string) []*bugsnagAPI.Event {
+	var events []*bugsnagAPI.Event
+	for _, event := range f.Events {
+		for _, e := range f.Errors {
+			if e.ID == event.ErrorID && e.ProjectID == projectID {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// release returns the release with the given ID, or nil.
+func (f *Fixtures) release(id string) *bugsnag.Release {
+	for _, releases := range f.Releases {
+		for _, release := range releases {
+			if release.ID == id {
+				return release
+			}
+		}
+	}
+	return nil
+}
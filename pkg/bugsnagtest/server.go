@@ -0,0 +1,444 @@
+// Package bugsnagtest provides a fake Bugsnag Data Access API serving fixtures, for tests and offline demos.
+package bugsnagtest
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+const (
+	// Token is the auth token accepted by the fake API.
+	Token = "fake-bugsnag-token"
+
+	defaultPerPage     = 30
+	maxPerPage         = 100
+	defaultSummarySize = 10
+)
+
+// pivotFields are the event fields the fake API breaks events down by in pivots, with their names.
+var pivotFields = []struct{ ID, Name string }{
+	{"app.version", "App Version"},
+	{"app.release_stage", "Release Stage"},
+	{"device.osName", "OS"},
+	{"device.browserName", "Browser"},
+	{"user.id", "User"},
+}
+
+// Fault makes the fake API fail the requests matching it.
+type Fault struct {
+	// Method is the HTTP method of the failing requests, any when empty.
+	Method string
+	// Path is the prefix of the paths of the failing requests, e.g. /projects/abc/errors, any when empty.
+	Path string
+	// Status is the HTTP status code of the failure.
+	Status int
+	// Message is the error message of the failure, the status text when empty.
+	Message string
+	// RetryAfter is sent in the Retry-After header when set.
+	RetryAfter time.Duration
+	// Times is the number of requests failing, all of them when zero.
+	Times int
+}
+
+// Server is a fake Bugsnag Data Access API serving fixtures. It supports listing organizations, their projects,
+// and the errors, events and releases of projects, with pagination and filters, retrieving each of them, breaking
+// their events down in pivots, and updating the status of errors, which changes the fixtures. Filters on fields it
+// does not know are ignored.
+type Server struct {
+	// URL is the base URL of the fake API, http://127.0.0.1:port.
+	URL string
+
+	listener net.Listener
+	server   *http.Server
+
+	mu       sync.Mutex
+	fixtures *Fixtures
+	faults   []*Fault
+	requests []string
+}
+
+// NewServer starts a fake Bugsnag API serving fixtures. It is closed with Close.
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{fixtures: fixtures}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/organizations", s.listOrganizations)
+	mux.HandleFunc("GET /organizations/{id}", s.getOrganization)
+	mux.HandleFunc("GET /organizations/{id}/projects", s.listProjects)
+	mux.HandleFunc("GET /projects/{id}", s.getProject)
+	mux.HandleFunc("GET /projects/{id}/errors", s.listErrors)
+	mux.HandleFunc("GET /projects/{id}/errors/{error_id}", s.getError)
+	mux.HandleFunc("PATCH /projects/{id}/errors/{error_id}", s.updateError)
+	mux.HandleFunc("GET /projects/{id}/errors/{error_id}/events", s.listEvents)
+	mux.HandleFunc("GET /projects/{id}/events", s.listEvents)
+	mux.HandleFunc("GET /projects/{id}/events/{event_id}", s.getEvent)
+	mux.HandleFunc("GET /projects/{id}/releases", s.listReleases)
+	mux.HandleFunc("GET /releases/{id}", s.getRelease)
+	mux.HandleFunc("GET /projects/{id}/pivots", s.listPivots)
+	mux.HandleFunc("GET /projects/{id}/errors/{error_id}/pivots", s.listPivots)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Not found")
+	})
+
+	// Listen without net/http/httptest, which would link the testing package into the server binary
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("bugsnagtest: failed to listen on a port: " + err.Error())
+	}
+	s.URL = "http://" + listener.Addr().String()
+	s.listener = listener
+	s.server = &http.Server{Handler: s.middleware(mux), ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+	return s
+}
+
+// Close stops the fake API, closing its listener and connections.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// APIClient returns a Bugsnag API client for the fake API.
+func (s *Server) APIClient() *bugsnagAPI.Client {
+	return bugsnagAPI.NewClient(Token, bugsnagAPI.WithBaseURL(s.URL))
+}
+
+// Fail makes the requests matching f fail, until it has failed f.Times requests.
+func (s *Server) Fail(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the requests received so far, as "METHOD /path?query".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// middleware records requests, checks their auth token, and injects the faults.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+		fault := s.matchFault(r)
+		s.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "token "+Token {
+			writeError(w, http.StatusUnauthorized, "Invalid auth token")
+			return
+		}
+		if fault != nil {
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+			}
+			writeError(w, fault.Status, cmp.Or(fault.Message, http.StatusText(fault.Status)))
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchFault returns the first fault matching r, counting it against its Times. s.mu must be held.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if (f.Method != "" && f.Method != r.Method) || !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) listOrganizations(w http.ResponseWriter, r *http.Request) {
+	writePage(w, r, s.fixtures.Organizations)
+}
+
+func (s *Server) getOrganization(w http.ResponseWriter, r *http.Request) {
+	for _, org := range s.fixtures.Organizations {
+		if org.ID == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, org)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Organization not found")
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	var projects []*bugsnagAPI.Project
+	for _, project := range s.fixtures.Projects {
+		if project.OrganizationID == r.PathValue("id") {
+			projects = append(projects, project)
+		}
+	}
+	writePage(w, r, projects)
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	project := s.fixtures.project(r.PathValue("id"))
+	if project == nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) listErrors(w http.ResponseWriter, r *http.Request) {
+	projectID := r.PathValue("id")
+	if s.fixtures.project(projectID) == nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	filters := parseFilters(r.URL.Query())
+	var errs []*bugsnagAPI.Error
+	for _, e := range s.fixtures.Errors {
+		if e.ProjectID == projectID && filters.matchError(e) {
+			errs = append(errs, e)
+		}
+	}
+
+	field := cmp.Or(r.URL.Query().Get("sort"), "last_seen")
+	desc := r.URL.Query().Get("direction") != "asc"
+	if field != "unsorted" {
+		slices.SortStableFunc(errs, func(a, b *bugsnagAPI.Error) int {
+			var c int
+			switch field {
+			case "first_seen":
+				c = a.FirstSeen.Compare(b.FirstSeen)
+			case "users":
+				c = cmp.Compare(a.Users, b.Users)
+			case "events":
+				c = cmp.Compare(a.Events, b.Events)
+			default:
+				c = a.LastSeen.Compare(b.LastSeen)
+			}
+			if desc {
+				return -c
+			}
+			return c
+		})
+	}
+	writePage(w, r, errs)
+}
+
+func (s *Server) getError(w http.ResponseWriter, r *http.Request) {
+	e := s.fixtures.error(r.PathValue("id"), r.PathValue("error_id"))
+	if e == nil {
+		writeError(w, http.StatusNotFound, "Error could not be found")
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+// updateError applies the status operations of an error update.
+func (s *Server) updateError(w http.ResponseWriter, r *http.Request) {
+	e := s.fixtures.error(r.PathValue("id"), r.PathValue("error_id"))
+	if e == nil {
+		writeError(w, http.StatusNotFound, "Error could not be found")
+		return
+	}
+	var update bugsnagAPI.ErrorUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	switch update.Operation {
+	case "fix":
+		e.Status = "fixed"
+	case "ignore":
+		e.Status = "ignored"
+	case "open":
+		e.Status = "open"
+	case "snooze":
+		if update.ReopenRules == nil {
+			writeError(w, http.StatusBadRequest, "reopen_rules is required to snooze an error")
+			return
+		}
+		e.Status = "snoozed"
+		e.ReopenRules = bugsnagAPI.ReopenRules{
+			ReopenIf:              update.ReopenRules.ReopenIf,
+			Seconds:               update.ReopenRules.Seconds,
+			Occurrences:           update.ReopenRules.Occurrences,
+			Hours:                 update.ReopenRules.Hours,
+			AdditionalOccurrences: update.ReopenRules.AdditionalOccurrences,
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported operation "+strconv.Quote(update.Operation))
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+// listEvents lists the events of a project, or of one of its errors, most recent first.
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	events, ok := s.selectEvents(w, r)
+	if !ok {
+		return
+	}
+	slices.SortStableFunc(events, func(a, b *bugsnagAPI.Event) int { return b.ReceivedAt.Compare(a.ReceivedAt) })
+	writePage(w, r, events)
+}
+
+// listPivots breaks down the events of a project, or of one of its errors, by the fields in pivotFields,
+// or by those of them in the pivots[] query parameters.
+func (s *Server) listPivots(w http.ResponseWriter, r *http.Request) {
+	events, ok := s.selectEvents(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	summarySize, err := strconv.Atoi(query.Get("summary_size"))
+	if err != nil || summarySize <= 0 {
+		summarySize = defaultSummarySize
+	}
+
+	pivots := []*bugsnagAPI.Pivot{}
+	for _, field := range pivotFields {
+		if requested := query["pivots[]"]; len(requested) > 0 && !slices.Contains(requested, field.ID) {
+			continue
+		}
+		pivot := &bugsnagAPI.Pivot{EventFieldDisplayID: field.ID, Name: field.Name}
+		counts := map[string]int{}
+		for _, event := range events {
+			if value := eventFields(event)[field.ID][0]; value != "" {
+				counts[value]++
+			} else {
+				pivot.Summary.NoValue++
+			}
+		}
+		// Most frequent values first, the rest are counted as other
+		values := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+			return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+		})
+		pivot.Cardinality = len(values)
+		for i, value := range values {
+			if i < summarySize {
+				pivot.Summary.List = append(pivot.Summary.List, bugsnagAPI.List{Value: value, Events: strconv.Itoa(counts[value])})
+			} else {
+				pivot.Summary.Other += counts[value]
+			}
+		}
+		pivots = append(pivots, pivot)
+	}
+	writeJSON(w, http.StatusOK, pivots)
+}
+
+// selectEvents returns the events of the project, or of the error, of the request path that match its filters.
+// It writes a not found response and returns false when the project or error does not exist.
+func (s *Server) selectEvents(w http.ResponseWriter, r *http.Request) ([]*bugsnagAPI.Event, bool) {
+	projectID := r.PathValue("id")
+	if s.fixtures.project(projectID) == nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return nil, false
+	}
+	errorID := r.PathValue("error_id")
+	if errorID != "" && s.fixtures.error(projectID, errorID) == nil {
+		writeError(w, http.StatusNotFound, "Error could not be found")
+		return nil, false
+	}
+
+	filters := parseFilters(r.URL.Query())
+	var events []*bugsnagAPI.Event
+	for _, event := range s.fixtures.projectEvents(projectID) {
+		if (errorID == "" || event.ErrorID == errorID) && filters.matchEvent(event) {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
+
+func (s *Server) getEvent(w http.ResponseWriter, r *http.Request) {
+	for _, event := range s.fixtures.projectEvents(r.PathValue("id")) {
+		if event.ID == r.PathValue("event_id") {
+			writeJSON(w, http.StatusOK, event)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Event could not be found")
+}
+
+// listReleases lists the releases of a project, most recent first, optionally of a single release stage.
+func (s *Server) listReleases(w http.ResponseWriter, r *http.Request) {
+	projectID := r.PathValue("id")
+	if s.fixtures.project(projectID) == nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	stage := r.URL.Query().Get("release_stage")
+	var releases []*bugsnag.Release
+	for _, release := range s.fixtures.Releases[projectID] {
+		if stage == "" || release.ReleaseStage.Name == stage {
+			releases = append(releases, release)
+		}
+	}
+	slices.SortStableFunc(releases, func(a, b *bugsnag.Release) int { return b.ReleaseTime.Compare(a.ReleaseTime) })
+	writePage(w, r, releases)
+}
+
+func (s *Server) getRelease(w http.ResponseWriter, r *http.Request) {
+	release := s.fixtures.release(r.PathValue("id"))
+	if release == nil {
+		writeError(w, http.StatusNotFound, "Release not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, release)
+}
+
+// writePage writes the page of items selected by the offset and per_page query parameters, with the
+// total count in the X-Total-Count header and a Link header to the next page, like the Bugsnag API.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	offset = min(max(offset, 0), len(items))
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	perPage = min(perPage, maxPerPage)
+	end := min(offset+perPage, len(items))
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	if end < len(items) {
+		query.Set("offset", strconv.Itoa(end))
+		next := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+	page := items[offset:end]
+	if page == nil {
+		page = []T{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format of the Bugsnag API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, bugsnagAPI.ErrorResponse{Errors: []string{message}})
+}
@@ -0,0 +1,175 @@
+package bugsnagtest
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+	ctx := context.Background()
+
+	events, page, err := bugsnag.ListPage[*bugsnagAPI.Event](ctx, server.APIClient(), "projects/"+APIProjectID+"/events", nil, bugsnag.PageOptions{PerPage: 2})
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(events) != 2 || events[0].ID != NilPointerEventID || page.TotalCount != 5 || page.NextCursor == "" {
+		t.Fatalf("first page = %d events starting with %s, page %+v, want the 2 most recent of 5 and a cursor", len(events), events[0].ID, page)
+	}
+
+	var ids []string
+	for cursor := page.NextCursor; cursor != ""; cursor = page.NextCursor {
+		events, page, err = bugsnag.ListPage[*bugsnagAPI.Event](ctx, server.APIClient(), "projects/"+APIProjectID+"/events", nil, bugsnag.PageOptions{PerPage: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+	}
+	if len(ids) != 3 || slices.Contains(ids, NilPointerEventID) {
+		t.Errorf("following pages = %v, want the 3 remaining events", ids)
+	}
+}
+
+func TestServerFilters(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+		expr string
+		want int
+	}{
+		{name: "errors by status", path: "errors", expr: "error.status=open", want: 2},
+		{name: "errors by excluded status", path: "errors", expr: "error.status!=open", want: 1},
+		{name: "errors by release stage", path: "errors", expr: "app.release_stage=staging", want: 1},
+		{name: "events by version", path: "events", expr: "app.version=1.4.1", want: 3},
+		{name: "events by either user", path: "events", expr: "user.id=u-1042 user.id=u-3001", want: 3},
+		{name: "events since", path: "events", expr: "event.since=2025-06-01T00:00:00Z", want: 3},
+		{name: "events before", path: "events", expr: "event.before=2025-06-01T00:00:00Z", want: 2},
+		{name: "unknown fields are ignored", path: "events", expr: "request.url=/health", want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := bugsnag.ParseFilterExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilterExpression() error = %v", err)
+			}
+			var items []map[string]any
+			resp, err := bugsnag.List(context.Background(), server.APIClient(), "projects/"+APIProjectID+"/"+tt.path, bugsnag.EncodeFilters(filters), &items)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(items) != tt.want || resp.Header.Get("X-Total-Count") != strconv.Itoa(tt.want) {
+				t.Errorf("List() = %d items, total %s, want %d", len(items), resp.Header.Get("X-Total-Count"), tt.want)
+			}
+		})
+	}
+}
+
+func TestServerPivots(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+
+	var pivots []*bugsnagAPI.Pivot
+	query := map[string][]string{"pivots[]": {"app.version"}, "summary_size": {"1"}}
+	if _, err := bugsnag.List(context.Background(), server.APIClient(), "projects/"+APIProjectID+"/pivots", query, &pivots); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(pivots) != 1 || pivots[0].EventFieldDisplayID != "app.version" {
+		t.Fatalf("pivots = %+v, want the app.version pivot only", pivots)
+	}
+	summary := pivots[0].Summary
+	if len(summary.List) != 1 || summary.List[0].Events == "" || summary.Other == 0 {
+		t.Errorf("summary = %+v, want the top value and the others counted as other", summary)
+	}
+}
+
+func TestServerUpdateError(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+	client := server.APIClient()
+	ctx := context.Background()
+
+	updated, _, err := client.Errors.UpdateError(ctx, APIProjectID, NilPointerErrorID, &bugsnagAPI.ErrorUpdateRequest{Operation: "fix"})
+	if err != nil || updated.Status != "fixed" {
+		t.Fatalf("UpdateError() = %v, %v, want the error fixed", updated, err)
+	}
+	if got, _, err := client.Errors.GetError(ctx, APIProjectID, NilPointerErrorID); err != nil || got.Status != "fixed" {
+		t.Errorf("GetError() after update = %v, %v, want the error fixed", got, err)
+	}
+	if _, _, err := client.Errors.UpdateError(ctx, APIProjectID, NilPointerErrorID, &bugsnagAPI.ErrorUpdateRequest{Operation: "snooze"}); err == nil {
+		t.Error("UpdateError() snoozing without reopen rules succeeded, want an error")
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("invalid token", func(t *testing.T) {
+		client := bugsnagAPI.NewClient("wrong", bugsnagAPI.WithBaseURL(server.URL))
+		if _, _, err := client.Projects.GetProject(ctx, APIProjectID); err == nil || !strings.Contains(err.Error(), "Invalid auth token") {
+			t.Errorf("GetProject() error = %v, want the token rejected", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, _, err := server.APIClient().Events.GetEvent(ctx, WebProjectID, NilPointerEventID); err == nil {
+			t.Error("GetEvent() of another project's event succeeded, want not found")
+		}
+	})
+
+	t.Run("injected faults", func(t *testing.T) {
+		server.Fail(Fault{Method: http.MethodGet, Path: "/projects/" + WebProjectID, Status: http.StatusServiceUnavailable, Times: 1})
+		client := bugsnagAPI.NewClient(Token, bugsnagAPI.WithBaseURL(server.URL), bugsnagAPI.WithHTTPClient(&http.Client{
+			Transport: bugsnag.RetryTransport(http.DefaultTransport, bugsnag.RetryOptions{MaxRetries: 1, BaseDelay: time.Millisecond}),
+		}))
+		if _, _, err := client.Projects.GetProject(ctx, WebProjectID); err != nil {
+			t.Errorf("GetProject() error = %v, want the failure retried", err)
+		}
+
+		server.Fail(Fault{Path: "/projects/" + WebProjectID, Status: http.StatusTooManyRequests, Message: "Slow down"})
+		if _, _, err := server.APIClient().Projects.GetProject(ctx, WebProjectID); err == nil || err.Error() != "Slow down" {
+			t.Errorf("GetProject() error = %v, want the injected failure", err)
+		}
+	})
+
+	want := "GET /projects/" + WebProjectID
+	if requests := server.Requests(); !slices.Contains(requests, want) {
+		t.Errorf("Requests() = %v, want %q", requests, want)
+	}
+}
+
+func TestFixturesRebase(t *testing.T) {
+	fixtures := DefaultFixtures()
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixtures.Rebase(now)
+
+	latest := fixtures.Events[0].ReceivedAt
+	for _, event := range fixtures.Events {
+		if event.ReceivedAt.After(latest) {
+			latest = event.ReceivedAt
+		}
+	}
+	if !latest.Equal(now) {
+		t.Errorf("latest event after Rebase() = %v, want %v", latest, now)
+	}
+	if e := fixtures.error(APIProjectID, NilPointerErrorID); !e.LastSeen.Equal(now) || !e.FirstSeen.Before(e.LastSeen) {
+		t.Errorf("error after Rebase() seen from %v to %v, want moved with the events", e.FirstSeen, e.LastSeen)
+	}
+}
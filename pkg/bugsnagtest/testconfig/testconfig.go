@@ -0,0 +1,36 @@
+// Package testconfig builds configurations for tests against the fake Bugsnag API of package bugsnagtest.
+// It is kept apart from bugsnagtest so that the server binary, which serves the fake API in demo mode,
+// does not link the testing package.
+package testconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+// New returns a configuration using the fake API, with every option at its default rather than read from
+// the environment the tests run in: all tools enabled, no persisted cache, and the default retries and budget.
+func New(t testing.TB, fake *bugsnagtest.Server) *config.Config {
+	t.Helper()
+	cfg, err := config.NewConfigFrom(nil)
+	if err != nil {
+		t.Fatalf("config.NewConfigFrom() error = %v", err)
+	}
+	cfg.UseEndpoint(fake.URL, bugsnagtest.Token)
+	return cfg
+}
+
+// NewFake starts a fake API serving the default fixtures, moved to the present so that relative time filters
+// such as since=24h match their events, and returns a configuration using it. The fake API is closed when the
+// test ends.
+func NewFake(t testing.TB) (*config.Config, *bugsnagtest.Server) {
+	t.Helper()
+	fixtures := bugsnagtest.DefaultFixtures()
+	fixtures.Rebase(time.Now().Truncate(time.Second))
+	fake := bugsnagtest.NewServer(fixtures)
+	t.Cleanup(fake.Close)
+	return New(t, fake), fake
+}
@@ -1,31 +1,219 @@
 package config
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/caarlos0/env/v11"
 
 	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
 )
 
 // Config holds the configuration for the application.
 type Config struct {
-	// bugsnag auth token
-	AuthToken string `env:"BUGSNAG_AUTH_TOKEN,required"`
+	// bugsnag auth token, used for the requests of sessions that do not provide their own
+	AuthToken string `env:"BUGSNAG_AUTH_TOKEN"`
 	// bugsnag endpoint
 	Endpoint string `env:"BUGSNAG_ENDPOINT" envDefault:"https://api.bugsnag.com"`
 
+	// bearer tokens / API keys accepted from clients of the network transports
+	AuthTokens []string `env:"MCP_AUTH_TOKENS"`
+	// JSON Web Key Set file used to validate JWT bearer tokens from clients of the network transports
+	AuthJWKSFile string `env:"MCP_AUTH_JWKS_FILE"`
+	// required issuer of JWT bearer tokens
+	AuthIssuer string `env:"MCP_AUTH_ISSUER"`
+	// required audience of JWT bearer tokens
+	AuthAudience string `env:"MCP_AUTH_AUDIENCE"`
+	// how long the client of a per-session bugsnag auth token is kept after its last use
+	SessionTTL time.Duration `env:"BUGSNAG_SESSION_TTL" envDefault:"1h"`
+
+	// hide the tools that change data in bugsnag
+	ReadOnly bool `env:"BUGSNAG_READ_ONLY"`
+	// IDs of the tools to advertise, all when empty
+	AllowedTools []string `env:"BUGSNAG_ALLOWED_TOOLS"`
+	// IDs of the tools to hide
+	DeniedTools []string `env:"BUGSNAG_DENIED_TOOLS"`
+
+	// number of bugsnag API responses cached, 0 disables the cache
+	CacheSize int `env:"BUGSNAG_CACHE_SIZE" envDefault:"1000"`
+	// how long the responses of each entity type are cached, overriding the defaults, e.g. errors:30s,events:0s
+	CacheTTLs map[string]time.Duration `env:"BUGSNAG_CACHE_TTLS"`
+	// file the cache is persisted to between runs, in memory only when empty
+	CacheFile string `env:"BUGSNAG_CACHE_FILE"`
+
+	// number of times rate limited or failed bugsnag API reads are retried
+	MaxRetries int `env:"BUGSNAG_MAX_RETRIES" envDefault:"3"`
+	// longest delay between retries, including the ones asked for by the bugsnag API
+	RetryMaxDelay time.Duration `env:"BUGSNAG_RETRY_MAX_DELAY" envDefault:"30s"`
+
+	// largest tool result in characters, not bytes (about 4 per token), 0 disables the budget; resources such as
+	// the event resource are not tool results and are never trimmed
+	MaxOutputChars int `env:"BUGSNAG_MAX_OUTPUT_CHARS" envDefault:"50000"`
+
+	// APIClient is the client for AuthToken, used by sessions that do not provide their own token
 	APIClient *bugsnagAPI.Client
+
+	httpClient *http.Client
+	resolver   *bugsnag.Resolver
+	sessions   *sessionCache
+	cache      *bugsnag.Cache
 }
 
 // NewConfig creates a new Config struct and populates it with environment variables.
 // It returns an error if any required environment variables are missing or if there is an error parsing them.
 func NewConfig() (*Config, error) {
+	return newConfig(env.Options{})
+}
+
+// NewConfigFrom creates a new Config struct populated with the given variables instead of the environment of
+// the process, e.g. so tests do not depend on the environment they run in. Options without a variable take
+// their defaults.
+func NewConfigFrom(environment map[string]string) (*Config, error) {
+	if environment == nil {
+		environment = map[string]string{}
+	}
+	return newConfig(env.Options{Environment: environment})
+}
+
+// newConfig creates a new Config struct populated with the variables of opts.
+func newConfig(opts env.Options) (*Config, error) {
 	cfg := &Config{}
-	if err := env.Parse(cfg); err != nil {
+	if err := env.ParseWithOptions(cfg, opts); err != nil {
 		return nil, err
 	}
-	cfg.APIClient = bugsnagAPI.NewClient(
-		cfg.AuthToken,
-		bugsnagAPI.WithBaseURL(cfg.Endpoint),
-	)
+
+	ttls := maps.Clone(bugsnag.DefaultCacheTTLs)
+	for entity, ttl := range cfg.CacheTTLs {
+		if _, ok := ttls[bugsnag.EntityType(entity)]; !ok {
+			return nil, fmt.Errorf("unknown entity type %q in BUGSNAG_CACHE_TTLS", entity)
+		}
+		ttls[bugsnag.EntityType(entity)] = ttl
+	}
+	cache, err := bugsnag.NewCache(bugsnag.CacheOptions{TTLs: ttls, MaxEntries: cfg.CacheSize, Path: cfg.CacheFile})
+	if err != nil {
+		return nil, err
+	}
+	cfg.cache = cache
+	retry := bugsnag.RetryTransport(http.DefaultTransport, bugsnag.RetryOptions{
+		MaxRetries: cfg.MaxRetries,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   cfg.RetryMaxDelay,
+	})
+	cfg.httpClient = &http.Client{Transport: cache.Transport(retry)}
+	cfg.newClients()
 	return cfg, nil
 }
+
+// UseEndpoint points the clients at another Bugsnag API endpoint with the given auth token, e.g. a fake API.
+func (c *Config) UseEndpoint(endpoint, token string) {
+	c.Endpoint = endpoint
+	c.AuthToken = token
+	c.newClients()
+}
+
+// newClients creates the API client and resolver for AuthToken, and the cache of per-session clients, for Endpoint.
+func (c *Config) newClients() {
+	c.APIClient = bugsnagAPI.NewClient(
+		c.AuthToken,
+		bugsnagAPI.WithBaseURL(c.Endpoint),
+		bugsnagAPI.WithHTTPClient(c.httpClient),
+	)
+	c.resolver = bugsnag.NewResolver(c.APIClient)
+	c.sessions = newSessionCache(c.Endpoint, c.httpClient, c.SessionTTL)
+}
+
+// Close persists the response cache to CacheFile, if set.
+func (c *Config) Close() error {
+	if c.cache == nil {
+		return nil
+	}
+	return c.cache.Save()
+}
+
+// RegisterFlags defines the command line flags overriding the options of c, with the values from the
+// environment as defaults.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.ReadOnly, "read-only", c.ReadOnly, "Hide the tools that change data in Bugsnag")
+	fs.Var((*listFlag)(&c.AllowedTools), "allow-tools", "Comma-separated IDs of the tools to advertise, all when empty")
+	fs.Var((*listFlag)(&c.DeniedTools), "deny-tools", "Comma-separated IDs of the tools to hide")
+}
+
+// ToolEnabled reports whether the tool with the given ID is advertised to clients. Tools that
+// change data in Bugsnag are hidden in read-only mode.
+func (c *Config) ToolEnabled(id string, mutates bool) bool {
+	if c.ReadOnly && mutates {
+		return false
+	}
+	if len(c.AllowedTools) > 0 && !containsTool(c.AllowedTools, id) {
+		return false
+	}
+	return !containsTool(c.DeniedTools, id)
+}
+
+// UnknownTools returns the IDs in the allow and deny lists that are not among the known tool IDs.
+func (c *Config) UnknownTools(known []string) []string {
+	var unknown []string
+	for _, id := range slices.Concat(c.AllowedTools, c.DeniedTools) {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(known, id) && !slices.Contains(unknown, id) {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
+
+// containsTool reports whether the list of tool IDs contains id, ignoring surrounding whitespace.
+func containsTool(list []string, id string) bool {
+	return slices.ContainsFunc(list, func(s string) bool { return strings.TrimSpace(s) == id })
+}
+
+// listFlag is a flag.Value for a comma-separated list.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+type sessionKey struct{}
+
+// WithBugsnagToken returns a copy of ctx whose Bugsnag requests are made with the given auth token
+// instead of AuthToken, so they are made as, and attributed to, the holder of the token.
+func (c *Config) WithBugsnagToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, c.sessions.get(token))
+}
+
+// Client returns the Bugsnag API client for the session of ctx.
+func (c *Config) Client(ctx context.Context) *bugsnagAPI.Client {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return s.client
+	}
+	return c.APIClient
+}
+
+// Resolver returns the resolver of organization and project slugs, names and URLs to IDs for the session of ctx.
+func (c *Config) Resolver(ctx context.Context) *bugsnag.Resolver {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return s.resolver
+	}
+	return c.resolver
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"flag"
+	"slices"
+	"testing"
+)
+
+func TestToolEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		id      string
+		mutates bool
+		want    bool
+	}{
+		{name: "default", id: "get_project_error", want: true},
+		{name: "mutating tool", id: "update_error_status", mutates: true, want: true},
+		{name: "read-only hides mutating tool", cfg: Config{ReadOnly: true}, id: "update_error_status", mutates: true, want: false},
+		{name: "read-only keeps reading tool", cfg: Config{ReadOnly: true}, id: "get_project_error", want: true},
+		{name: "allowed", cfg: Config{AllowedTools: []string{"list_releases", " get_project_error"}}, id: "get_project_error", want: true},
+		{name: "not allowed", cfg: Config{AllowedTools: []string{"list_releases"}}, id: "get_project_error", want: false},
+		{name: "denied", cfg: Config{DeniedTools: []string{"get_project_error"}}, id: "get_project_error", want: false},
+		{name: "denied wins over allowed", cfg: Config{AllowedTools: []string{"get_project_error"}, DeniedTools: []string{"get_project_error"}}, id: "get_project_error", want: false},
+		{name: "read-only wins over allowed", cfg: Config{ReadOnly: true, AllowedTools: []string{"bulk_update_errors"}}, id: "bulk_update_errors", mutates: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ToolEnabled(tt.id, tt.mutates); got != tt.want {
+				t.Errorf("ToolEnabled(%q, %t) = %t, want %t", tt.id, tt.mutates, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownTools(t *testing.T) {
+	cfg := Config{AllowedTools: []string{"list_releases", "list_relases"}, DeniedTools: []string{"list_relases", "get_eror"}}
+	got := cfg.UnknownTools([]string{"list_releases", "get_error"})
+	if want := []string{"list_relases", "get_eror"}; !slices.Equal(got, want) {
+		t.Errorf("UnknownTools() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	cfg := Config{ReadOnly: true, DeniedTools: []string{"get_release"}}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-allow-tools", "list_releases, get_release,"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if !cfg.ReadOnly {
+		t.Error("ReadOnly = false, want the value from the environment to be kept")
+	}
+	if want := []string{"list_releases", "get_release"}; !slices.Equal(cfg.AllowedTools, want) {
+		t.Errorf("AllowedTools = %v, want %v", cfg.AllowedTools, want)
+	}
+	if want := []string{"get_release"}; !slices.Equal(cfg.DeniedTools, want) {
+		t.Errorf("DeniedTools = %v, want %v", cfg.DeniedTools, want)
+	}
+}
+
+func TestNewConfigCacheTTLs(t *testing.T) {
+	t.Setenv("BUGSNAG_CACHE_TTLS", "errors:30s,events:0s")
+	if _, err := NewConfig(); err != nil {
+		t.Errorf("NewConfig() error = %v", err)
+	}
+
+	t.Setenv("BUGSNAG_CACHE_TTLS", "bugs:30s")
+	if _, err := NewConfig(); err == nil {
+		t.Error("NewConfig() with an unknown entity type error = nil, want an error")
+	}
+}
+
+func TestNewConfigFrom(t *testing.T) {
+	t.Setenv("BUGSNAG_READ_ONLY", "true")
+	t.Setenv("BUGSNAG_MAX_RETRIES", "9")
+
+	cfg, err := NewConfigFrom(nil)
+	if err != nil {
+		t.Fatalf("NewConfigFrom() error = %v", err)
+	}
+	if cfg.ReadOnly || cfg.MaxRetries != 3 || cfg.Endpoint != "https://api.bugsnag.com" {
+		t.Errorf("NewConfigFrom(nil) = read-only %t, %d retries, endpoint %q, want the defaults regardless of the environment",
+			cfg.ReadOnly, cfg.MaxRetries, cfg.Endpoint)
+	}
+
+	cfg, err = NewConfigFrom(map[string]string{"BUGSNAG_MAX_RETRIES": "5"})
+	if err != nil || cfg.MaxRetries != 5 {
+		t.Errorf("NewConfigFrom() = %d retries, %v, want 5 from the given variables", cfg.MaxRetries, err)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// session is the Bugsnag client and resolver used on behalf of the holder of an auth token.
+type session struct {
+	client   *bugsnagAPI.Client
+	resolver *bugsnag.Resolver
+	lastUsed time.Time
+}
+
+// sessionCache caches a session per auth token, so the organization and project index of the
+// resolver is shared by the requests made with the same token. Sessions unused for ttl are dropped.
+type sessionCache struct {
+	endpoint   string
+	httpClient *http.Client
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	sessions map[[sha256.Size]byte]*session
+}
+
+func newSessionCache(endpoint string, httpClient *http.Client, ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		endpoint:   endpoint,
+		httpClient: httpClient,
+		ttl:        ttl,
+		now:        time.Now,
+		sessions:   map[[sha256.Size]byte]*session{},
+	}
+}
+
+// get returns the session for the token, creating it if needed.
+func (c *sessionCache) get(token string) *session {
+	// Key by hash, so the tokens are not kept around as map keys
+	key := sha256.Sum256([]byte(token))
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, s := range c.sessions {
+		if now.Sub(s.lastUsed) > c.ttl {
+			delete(c.sessions, k)
+		}
+	}
+
+	s, ok := c.sessions[key]
+	if !ok {
+		client := bugsnagAPI.NewClient(token, bugsnagAPI.WithBaseURL(c.endpoint), bugsnagAPI.WithHTTPClient(c.httpClient))
+		s = &session{client: client, resolver: bugsnag.NewResolver(client)}
+		c.sessions[key] = s
+	}
+	s.lastUsed = now
+	return s
+}
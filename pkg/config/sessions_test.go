@@ -0,0 +1,59 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+func TestSessionCache(t *testing.T) {
+	now := time.Now()
+	cache := newSessionCache("https://api.example.com", http.DefaultClient, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	first := cache.get("token-1")
+	if got := cache.get("token-1"); got != first {
+		t.Error("get() with the same token returned a new session, want the cached one")
+	}
+	if got := cache.get("token-2"); got == first {
+		t.Error("get() with another token returned the same session")
+	}
+
+	now = now.Add(30 * time.Minute)
+	cache.get("token-2")
+	now = now.Add(45 * time.Minute)
+	cache.get("token-2")
+	if len(cache.sessions) != 1 {
+		t.Errorf("len(sessions) = %d, want the idle session to be dropped", len(cache.sessions))
+	}
+	if got := cache.get("token-1"); got == first {
+		t.Error("get() returned an expired session")
+	}
+}
+
+func TestConfigClient(t *testing.T) {
+	defaultClient := bugsnagAPI.NewClient("default")
+	cfg := &Config{
+		APIClient: defaultClient,
+		resolver:  bugsnag.NewResolver(defaultClient),
+		sessions:  newSessionCache("https://api.example.com", http.DefaultClient, time.Hour),
+	}
+
+	ctx := context.Background()
+	if cfg.Client(ctx) != defaultClient || cfg.Resolver(ctx) != cfg.resolver {
+		t.Error("Client() and Resolver() without a session token should return the defaults")
+	}
+
+	sessionCtx := cfg.WithBugsnagToken(ctx, "token-1")
+	if cfg.Client(sessionCtx) == defaultClient || cfg.Resolver(sessionCtx) == cfg.resolver {
+		t.Error("Client() and Resolver() with a session token returned the defaults")
+	}
+	if cfg.Client(cfg.WithBugsnagToken(ctx, "token-1")) != cfg.Client(sessionCtx) {
+		t.Error("Client() for the same session token should be cached")
+	}
+}
@@ -0,0 +1,421 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+	"github.com/sazap10/bugsnag-mcp/pkg/resources"
+	"github.com/sazap10/bugsnag-mcp/pkg/tools"
+)
+
+// Prompt IDs
+const (
+	TriageErrorPromptID       = "triage_error"
+	InvestigateSpikePromptID  = "investigate_spike"
+	ReleaseHealthPromptID     = "release_health"
+	WeeklyErrorReportPromptID = "weekly_error_report"
+)
+
+const (
+	// defaultSpikeWindow is the time window investigated when no window is given.
+	defaultSpikeWindow = "24h"
+	// reportWindow is the time window covered by the weekly error report.
+	reportWindow = "7d"
+	// topErrors is the number of errors pre-loaded for a project.
+	topErrors = 10
+	// reportErrors is the number of errors pre-loaded for each project of the weekly report.
+	reportErrors = 5
+	// maxReportProjects is the maximum number of projects covered by the weekly report.
+	maxReportProjects = 20
+)
+
+// NewTriageErrorPrompt returns the MCP prompt for triaging an error.
+func NewTriageErrorPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		TriageErrorPromptID,
+		mcp.WithPromptDescription("Triage a Bugsnag error: what happened, where, who is affected, the likely cause and what to do next. Pre-loads the error and the stack trace of its latest event"),
+		mcp.WithArgument(
+			"project",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID, slug, name or dashboard URL of the project the error belongs to"),
+		),
+		mcp.WithArgument(
+			"error",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID or dashboard URL of the error to triage"),
+		),
+	)
+}
+
+// HandleTriageErrorPrompt handles the request for the prompt triaging an error.
+func HandleTriageErrorPrompt(cfg *config.Config) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		projectID, err := resolveProject(ctx, cfg, req)
+		if err != nil {
+			return nil, err
+		}
+		errorRef, err := requireArgument(req, "error")
+		if err != nil {
+			return nil, err
+		}
+		errorID, err := bugsnag.ErrorIDFromIDOrLink(errorRef)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error ID or link: %v", err)
+		}
+
+		errorURI := resourceURI(resources.ErrorTemplateURI, projectID, errorID)
+		errorContents, err := resources.HandleErrorResource(cfg)(ctx, readResourceRequest(errorURI))
+		if err != nil {
+			return nil, err
+		}
+		events, _, err := bugsnag.ListPage[*bugsnagAPI.Event](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/errors/"+url.PathEscape(errorID)+"/events", nil, bugsnag.PageOptions{PerPage: 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the latest event: %v", err)
+		}
+
+		instructions := fmt.Sprintf("Triage the Bugsnag error %s of project %s. The error is attached below", errorID, projectID)
+		var attachments []mcp.PromptMessage
+		if len(events) > 0 && toolEnabled(cfg, tools.GetProjectEventToolID) {
+			eventURI := resourceURI(resources.EventTemplateURI, projectID, events[0].ID)
+			eventContents, err := resources.HandleEventResource(cfg)(ctx, readResourceRequest(eventURI))
+			if err != nil {
+				return nil, err
+			}
+			// The event itself is large: only its stack trace is attached, the rest can be retrieved when needed
+			attachments = embedResources(filterMIMEType(eventContents, "text/markdown"))
+			instructions += fmt.Sprintf(", with the stack trace of its latest event %s", events[0].ID)
+		}
+		instructions += ".\n\n" + steps(cfg,
+			step("Summarise the error in two or three sentences: its class and message, whether it is handled, its severity and status, and when it was first and last seen.", ""),
+			step("Find where it happens: walk the stack trace from the top and point out the first in-project frames, which are in bold, and the code around them.", ""),
+			step("Retrieve the full latest event if the stack trace is not enough, for its breadcrumbs, request, user and metadata.", tools.GetProjectEventToolID),
+			step("Assess the impact: how many events and users, and which app versions, release stages, operating systems or browsers it is concentrated in.", tools.GetErrorPivotsToolID),
+			step("Check whether it is getting worse, and whether it started or spiked after a deploy.", tools.GetErrorTrendToolID),
+			step("Explain the most likely root cause, quoting the frames and data supporting it, and say how confident you are.", ""),
+			step("Propose a fix, or the next debugging steps when the cause is unclear.", ""),
+			step("Recommend a priority (urgent, soon, backlog or ignore) and a status for the error.", ""),
+			step("Only change the status of the error once the user has confirmed it.", tools.UpdateErrorStatusToolID),
+		)
+
+		messages := []mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions))}
+		messages = append(messages, embedResources(errorContents)...)
+		messages = append(messages, attachments...)
+		return mcp.NewGetPromptResult(fmt.Sprintf("Triage of error %s", errorID), messages), nil
+	}
+}
+
+// NewInvestigateSpikePrompt returns the MCP prompt for investigating a spike of errors in a project.
+func NewInvestigateSpikePrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		InvestigateSpikePromptID,
+		mcp.WithPromptDescription("Investigate a spike of errors in a project: when it started, which errors drive it and why. Pre-loads the errors seen in the window"),
+		mcp.WithArgument(
+			"project",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID, slug, name or dashboard URL of the project to investigate"),
+		),
+		mcp.WithArgument(
+			"window",
+			mcp.ArgumentDescription("How far back to look: a relative time like 6h, 24h or 7d, or an RFC 3339 timestamp (default "+defaultSpikeWindow+")"),
+		),
+	)
+}
+
+// HandleInvestigateSpikePrompt handles the request for the prompt investigating a spike of errors in a project.
+func HandleInvestigateSpikePrompt(cfg *config.Config) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		projectID, err := resolveProject(ctx, cfg, req)
+		if err != nil {
+			return nil, err
+		}
+		window := req.Params.Arguments["window"]
+		if window == "" {
+			window = defaultSpikeWindow
+		}
+		since, err := bugsnag.ParseTime(window, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'window': %v", err)
+		}
+
+		errs, err := listErrors(ctx, cfg, projectID, topErrors, sinceFilter(since))
+		if err != nil {
+			return nil, err
+		}
+
+		instructions := fmt.Sprintf("Investigate the spike of errors in the Bugsnag project %s since %s. The %d errors with the most events seen since then are attached below.\n\n",
+			projectID, since.UTC().Format(time.RFC3339), len(errs))
+		instructions += steps(cfg,
+			step("Find when the spike started and how large it is compared to the usual volume, using the event trend of the project over the window and the period before it.", tools.GetProjectTrendToolID),
+			step("Identify the errors driving it: errors first seen in the window, and known errors whose occurrences jumped.", ""),
+			step("Compare the trend of the main errors with the project's, to tell whether they started together.", tools.GetErrorTrendToolID),
+			step("Check whether the spike lines up with a release, and which app version introduced it.", tools.ListReleasesToolID),
+			step("Look for what the spiking events have in common: app version, release stage, operating system, browser or users.", tools.GetErrorPivotsToolID),
+			step("Explain the most likely cause, e.g. a deploy, a dependency outage, a traffic surge or a single noisy client, with the evidence.", ""),
+			step("Recommend immediate actions, e.g. a rollback or a fix to prioritise, and which errors to triage first.", ""),
+		)
+
+		messages := []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions)),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(formatErrors(fmt.Sprintf("Errors seen since %s", window), errs, since))),
+		}
+		return mcp.NewGetPromptResult(fmt.Sprintf("Investigation of the errors of project %s since %s", projectID, window), messages), nil
+	}
+}
+
+// NewReleaseHealthPrompt returns the MCP prompt for assessing the health of a release.
+func NewReleaseHealthPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		ReleaseHealthPromptID,
+		mcp.WithPromptDescription("Assess the health of a release of a project: its stability, the errors it introduced and whether it is safe to roll out further. Pre-loads the errors seen in that version"),
+		mcp.WithArgument(
+			"project",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID, slug, name or dashboard URL of the project"),
+		),
+		mcp.WithArgument(
+			"version",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The app version of the release, e.g. 1.4.1"),
+		),
+	)
+}
+
+// HandleReleaseHealthPrompt handles the request for the prompt assessing the health of a release.
+func HandleReleaseHealthPrompt(cfg *config.Config) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		projectID, err := resolveProject(ctx, cfg, req)
+		if err != nil {
+			return nil, err
+		}
+		version, err := requireArgument(req, "version")
+		if err != nil {
+			return nil, err
+		}
+
+		errs, err := listErrors(ctx, cfg, projectID, topErrors, bugsnagAPI.Filter{Key: "app.version", Type: bugsnag.FilterTypeEqual, Value: version})
+		if err != nil {
+			return nil, err
+		}
+
+		instructions := fmt.Sprintf("Assess the health of version %s of the Bugsnag project %s. The %d errors with the most events seen in this version are attached below.\n\n",
+			version, projectID, len(errs))
+		instructions += steps(cfg,
+			step("Find the release of this version in each release stage, and the release before it.", tools.ListReleasesToolID),
+			step("Compare the crash-free sessions and users of this release with the previous one.", tools.GetReleaseStabilityToolID),
+			step("Separate the errors introduced in this version from those it inherited, and point out any regression.", ""),
+			step("For the main errors, check whether they are specific to this version or spread across versions.", tools.GetErrorPivotsToolID),
+			step("Give a verdict: healthy, needs attention, or unhealthy, with the numbers supporting it.", ""),
+			step("Recommend whether to continue the rollout, pause it or roll back, and which errors must be fixed first.", ""),
+		)
+
+		messages := []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions)),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(formatErrors(fmt.Sprintf("Errors seen in version %s", version), errs, time.Time{}))),
+		}
+		return mcp.NewGetPromptResult(fmt.Sprintf("Health of version %s of project %s", version, projectID), messages), nil
+	}
+}
+
+// NewWeeklyErrorReportPrompt returns the MCP prompt for writing the weekly error report of an organization.
+func NewWeeklyErrorReportPrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		WeeklyErrorReportPromptID,
+		mcp.WithPromptDescription("Write the weekly error report of an organization: the top and new errors of each project over the last 7 days. Pre-loads them"),
+		mcp.WithArgument(
+			"org",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The ID, slug, name or dashboard URL of the organization"),
+		),
+	)
+}
+
+// HandleWeeklyErrorReportPrompt handles the request for the prompt writing the weekly error report of an organization.
+func HandleWeeklyErrorReportPrompt(cfg *config.Config) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		orgRef, err := requireArgument(req, "org")
+		if err != nil {
+			return nil, err
+		}
+		orgID, err := cfg.Resolver(ctx).ResolveOrganization(ctx, orgRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve organization: %v", err)
+		}
+		since, err := bugsnag.ParseTime(reportWindow, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		projects, page, err := bugsnag.ListPage[*bugsnagAPI.Project](ctx, cfg.Client(ctx), "organizations/"+url.PathEscape(orgID)+"/projects", nil, bugsnag.PageOptions{
+			MaxItems: maxReportProjects,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve projects: %v", err)
+		}
+
+		instructions := fmt.Sprintf("Write the weekly error report of the Bugsnag organization %s, covering the week since %s. The errors with the most events seen this week in each of its projects are attached below",
+			orgID, since.UTC().Format(time.RFC3339))
+		if page.NextCursor != "" {
+			instructions += fmt.Sprintf(". Only the first %d projects are included, say so in the report", len(projects))
+		}
+		instructions += ".\n\n" + steps(cfg,
+			step("Open with a short summary: the overall trend, and the two or three things the team should know.", ""),
+			step("For each project with errors, list its top errors with their events and users, and call out the errors first seen this week.", ""),
+			step("Flag regressions and errors that are spiking; check the trend of the ones that look like they are.", tools.GetErrorTrendToolID),
+			step("Mention the projects without errors this week in one line.", ""),
+			step("End with recommendations: which errors to fix first, and which are noise that could be ignored or snoozed.", ""),
+			step("Write it in Markdown, in a form that can be shared with the team as is.", ""),
+		)
+
+		messages := []mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions))}
+		for _, project := range projects {
+			errs, err := listErrors(ctx, cfg, project.ID, reportErrors, sinceFilter(since))
+			if err != nil {
+				return nil, fmt.Errorf("project %s: %v", project.Name, err)
+			}
+			title := fmt.Sprintf("Project %s (%s): errors seen this week", project.Name, project.ID)
+			messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(formatErrors(title, errs, since))))
+		}
+		return mcp.NewGetPromptResult(fmt.Sprintf("Weekly error report of organization %s", orgID), messages), nil
+	}
+}
+
+// toolEnabled reports whether the tool with the given ID is enabled by the configuration.
+func toolEnabled(cfg *config.Config, toolID string) bool {
+	return cfg.ToolEnabled(toolID, tools.Mutates(toolID))
+}
+
+// requireArgument returns the value of a required prompt argument.
+func requireArgument(req mcp.GetPromptRequest, name string) (string, error) {
+	value := strings.TrimSpace(req.Params.Arguments[name])
+	if value == "" {
+		return "", fmt.Errorf("missing required argument '%s'", name)
+	}
+	return value, nil
+}
+
+// resolveProject returns the ID of the project referred to by the project argument.
+func resolveProject(ctx context.Context, cfg *config.Config, req mcp.GetPromptRequest) (string, error) {
+	ref, err := requireArgument(req, "project")
+	if err != nil {
+		return "", err
+	}
+	projectID, err := cfg.Resolver(ctx).ResolveProject(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project: %v", err)
+	}
+	return projectID, nil
+}
+
+// resourceURI fills the placeholders of a resource template URI with ids, in order.
+func resourceURI(template string, ids ...string) string {
+	uri := template
+	for _, id := range ids {
+		start, end := strings.Index(uri, "{"), strings.Index(uri, "}")
+		uri = uri[:start] + url.PathEscape(id) + uri[end+1:]
+	}
+	return uri
+}
+
+// readResourceRequest returns the request to read the resource at uri.
+func readResourceRequest(uri string) mcp.ReadResourceRequest {
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	return req
+}
+
+// embedResources returns the resource contents as user messages, so the model gets them with the prompt.
+func embedResources(contents []mcp.ResourceContents) []mcp.PromptMessage {
+	messages := make([]mcp.PromptMessage, 0, len(contents))
+	for _, c := range contents {
+		messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(c)))
+	}
+	return messages
+}
+
+// filterMIMEType returns the text contents with the given MIME type.
+func filterMIMEType(contents []mcp.ResourceContents, mimeType string) []mcp.ResourceContents {
+	var filtered []mcp.ResourceContents
+	for _, c := range contents {
+		if text, ok := c.(mcp.TextResourceContents); ok && text.MIMEType == mimeType {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// sinceFilter returns the filter on the events received after since.
+func sinceFilter(since time.Time) bugsnagAPI.Filter {
+	return bugsnagAPI.Filter{Key: "event.since", Type: bugsnag.FilterTypeEqual, Value: since.UTC().Format(time.RFC3339)}
+}
+
+// listErrors retrieves up to limit errors of a project matching the filters, with the most events first.
+func listErrors(ctx context.Context, cfg *config.Config, projectID string, limit int, filters ...bugsnagAPI.Filter) ([]*bugsnagAPI.Error, error) {
+	query := bugsnag.EncodeFilters(filters)
+	query.Set("sort", "events")
+	errs, _, err := bugsnag.ListPage[*bugsnagAPI.Error](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/errors", query, bugsnag.PageOptions{
+		PerPage: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve errors: %v", err)
+	}
+	return errs, nil
+}
+
+// formatErrors renders errors as a Markdown list under a title, one line per error. Errors first seen
+// after newSince are marked as new, unless it is zero.
+func formatErrors(title string, errs []*bugsnagAPI.Error, newSince time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", title)
+	if len(errs) == 0 {
+		b.WriteString("No errors.\n")
+		return b.String()
+	}
+	for _, e := range errs {
+		fmt.Fprintf(&b, "- `%s` %s: %s", e.ID, e.ErrorClass, e.Message)
+		if !newSince.IsZero() && e.FirstSeen.After(newSince) {
+			b.WriteString(" **(new)**")
+		}
+		fmt.Fprintf(&b, " · %d events · %d users · %s · %s · first seen %s · last seen %s\n",
+			e.Events, e.Users, e.Status, e.Severity, e.FirstSeen.UTC().Format(time.RFC3339), e.LastSeen.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// promptStep is an instruction of a prompt, possibly relying on a tool.
+type promptStep struct {
+	text   string
+	toolID string
+}
+
+// step returns an instruction relying on the tool with the given ID, or on none when it is empty.
+func step(text, toolID string) promptStep {
+	return promptStep{text: text, toolID: toolID}
+}
+
+// steps renders the instructions as a numbered list, naming the tool each one relies on. Instructions
+// relying on a tool disabled by the configuration are left out, so the model is never told to call it.
+func steps(cfg *config.Config, all ...promptStep) string {
+	var b strings.Builder
+	n := 0
+	for _, s := range all {
+		if s.toolID != "" && !toolEnabled(cfg, s.toolID) {
+			continue
+		}
+		n++
+		fmt.Fprintf(&b, "%d. %s", n, s.text)
+		if s.toolID != "" {
+			fmt.Fprintf(&b, " Use the `%s` tool.", s.toolID)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
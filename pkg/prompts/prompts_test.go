@@ -0,0 +1,177 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
+	"github.com/sazap10/bugsnag-mcp/pkg/tools"
+)
+
+// getPrompt gets a prompt with args, returning the text of each of its messages.
+func getPrompt(t *testing.T, handler server.PromptHandlerFunc, args map[string]string) ([]string, error) {
+	t.Helper()
+	req := mcp.GetPromptRequest{}
+	req.Params.Arguments = args
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	for _, message := range result.Messages {
+		if message.Role != mcp.RoleUser {
+			t.Errorf("message role = %s, want %s", message.Role, mcp.RoleUser)
+		}
+		switch content := message.Content.(type) {
+		case mcp.TextContent:
+			texts = append(texts, content.Text)
+		case mcp.EmbeddedResource:
+			resource := content.Resource.(mcp.TextResourceContents)
+			texts = append(texts, resource.MIMEType+" "+resource.URI+"\n"+resource.Text)
+		default:
+			t.Fatalf("message content = %#v, want text or a resource", content)
+		}
+	}
+	return texts, nil
+}
+
+func TestPromptHandlers(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	errorLink := "https://app.bugsnag.com/acme/api/errors/" + bugsnagtest.NilPointerErrorID + "?event_id=" + bugsnagtest.NilPointerEventID
+
+	tests := []struct {
+		name    string
+		handler server.PromptHandlerFunc
+		args    map[string]string
+		want    [][]string
+		wantErr string
+	}{
+		{
+			name:    "triage error",
+			handler: HandleTriageErrorPrompt(cfg),
+			args:    map[string]string{"project": "acme/api", "error": errorLink},
+			want: [][]string{
+				{"Triage the Bugsnag error " + bugsnagtest.NilPointerErrorID, "with the stack trace of its latest event", "Use the `get_error_pivots` tool.", "Use the `update_error_status` tool."},
+				{"application/json bugsnag://projects/" + bugsnagtest.APIProjectID + "/errors/" + bugsnagtest.NilPointerErrorID, `"error_class":"runtime.Error"`},
+				{"text/markdown bugsnag://projects/" + bugsnagtest.APIProjectID + "/events/", "**internal/users/store.go:57**"},
+			},
+		},
+		{
+			name:    "investigate spike",
+			handler: HandleInvestigateSpikePrompt(cfg),
+			args:    map[string]string{"project": "API", "window": "2d"},
+			want: [][]string{
+				{"Investigate the spike of errors in the Bugsnag project " + bugsnagtest.APIProjectID, "Use the `get_project_trend` tool."},
+				{"## Errors seen since 2d", "`" + bugsnagtest.NilPointerErrorID + "` runtime.Error: invalid memory address or nil pointer dereference"},
+			},
+		},
+		{
+			name:    "release health",
+			handler: HandleReleaseHealthPrompt(cfg),
+			args:    map[string]string{"project": "acme/api", "version": "1.4.1"},
+			want: [][]string{
+				{"Assess the health of version 1.4.1", "Use the `get_release_stability` tool."},
+				{"## Errors seen in version 1.4.1", bugsnagtest.NilPointerErrorID},
+			},
+		},
+		{
+			name:    "weekly error report",
+			handler: HandleWeeklyErrorReportPrompt(cfg),
+			args:    map[string]string{"org": "acme"},
+			want: [][]string{
+				{"Write the weekly error report of the Bugsnag organization " + bugsnagtest.AcmeOrgID},
+				{"## Project Web (" + bugsnagtest.WebProjectID + "): errors seen this week"},
+				{"## Project API (" + bugsnagtest.APIProjectID + "): errors seen this week", bugsnagtest.NilPointerErrorID},
+			},
+		},
+		{
+			name:    "missing argument",
+			handler: HandleReleaseHealthPrompt(cfg),
+			args:    map[string]string{"project": "acme/api"},
+			wantErr: "missing required argument 'version'",
+		},
+		{
+			name:    "unknown project",
+			handler: HandleInvestigateSpikePrompt(cfg),
+			args:    map[string]string{"project": "acme/billing"},
+			wantErr: "failed to resolve project",
+		},
+		{
+			name:    "invalid window",
+			handler: HandleInvestigateSpikePrompt(cfg),
+			args:    map[string]string{"project": "acme/api", "window": "yesterday"},
+			wantErr: "invalid 'window'",
+		},
+		{
+			name:    "link to a project",
+			handler: HandleTriageErrorPrompt(cfg),
+			args:    map[string]string{"project": "acme/api", "error": "https://app.bugsnag.com/acme/api"},
+			wantErr: "does not point to an error",
+		},
+		{
+			name:    "relative link",
+			handler: HandleTriageErrorPrompt(cfg),
+			args:    map[string]string{"project": "acme/api", "error": "acme/api/errors/" + bugsnagtest.NilPointerErrorID},
+			wantErr: "invalid error ID or link",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPrompt(t, tt.handler, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("prompt error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prompt error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("prompt = %d messages, want %d:\n%s", len(got), len(tt.want), strings.Join(got, "\n---\n"))
+			}
+			for i, want := range tt.want {
+				for _, w := range want {
+					if !strings.Contains(got[i], w) {
+						t.Errorf("message %d does not contain %q:\n%s", i, w, got[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPromptStepsFollowEnabledTools(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	cfg.ReadOnly = true
+	cfg.DeniedTools = []string{tools.GetProjectEventToolID}
+
+	got, err := getPrompt(t, HandleTriageErrorPrompt(cfg), map[string]string{"project": "acme/api", "error": bugsnagtest.NilPointerErrorID})
+	if err != nil {
+		t.Fatalf("prompt error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("prompt = %d messages, want the instructions and the error without the stack trace", len(got))
+	}
+	for _, hidden := range []string{tools.UpdateErrorStatusToolID, tools.GetProjectEventToolID} {
+		if strings.Contains(got[0], hidden) {
+			t.Errorf("instructions mention the disabled tool %s:\n%s", hidden, got[0])
+		}
+	}
+	if !strings.Contains(got[0], "1. Summarise the error") || !strings.Contains(got[0], "3. Assess the impact") {
+		t.Errorf("instructions are not numbered in order:\n%s", got[0])
+	}
+}
+
+func TestResourceURI(t *testing.T) {
+	got := resourceURI("bugsnag://projects/{project_id}/events/{id}", "p1", "e/1")
+	if want := "bugsnag://projects/p1/events/e%2F1"; got != want {
+		t.Errorf("resourceURI() = %s, want %s", got, want)
+	}
+}
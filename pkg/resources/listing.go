@@ -0,0 +1,228 @@
+package resources
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+const (
+	// maxListedErrors is the number of open errors listed as resources for each project, most recently seen first.
+	maxListedErrors = 20
+	// errorProjectsPerPage is the number of projects whose open errors are listed on each page of resources,
+	// which is also the number of API calls made for the page.
+	errorProjectsPerPage = 5
+	// maxErrorProjects is the number of projects with open errors whose errors are listed, capping the API calls
+	// made to list every page of resources.
+	maxErrorProjects = 50
+	// maxNameMessage is the length error messages are truncated to in resource names.
+	maxNameMessage = 80
+)
+
+// ListProjectResources returns the projects of the current user as concrete resources of the project
+// template, ordered by organization and project name, from the listing shared with the resolver. With
+// includeErrors, it also returns the cursor of the page listing the open errors of the first projects, or ""
+// if no project has any.
+func ListProjectResources(ctx context.Context, cfg *config.Config, includeErrors bool) ([]mcp.Resource, mcp.Cursor, error) {
+	projects, err := listProjects(ctx, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	withErrors := projectsWithErrors(projects)
+
+	listed := make([]mcp.Resource, 0, len(projects))
+	for _, p := range projects {
+		listed = append(listed, newProjectResource(p.org, p.project, includeErrors && slices.Contains(withErrors, p)))
+	}
+	if !includeErrors || len(withErrors) == 0 {
+		return listed, "", nil
+	}
+	return listed, encodeErrorsCursor(withErrors[0].project.ID), nil
+}
+
+// IsErrorsCursor reports whether cursor is that of a page listing open errors, as returned by
+// ListProjectResources and ListErrorResources.
+func IsErrorsCursor(cursor mcp.Cursor) bool {
+	_, ok := parseErrorsCursor(cursor)
+	return ok
+}
+
+// ListErrorResources returns the page of resources at cursor: the most recently seen open errors of up to
+// errorProjectsPerPage projects, as concrete resources of the error template, and the cursor of the next
+// page, or "" on the last one.
+func ListErrorResources(ctx context.Context, cfg *config.Config, cursor mcp.Cursor) ([]mcp.Resource, mcp.Cursor, error) {
+	projectID, ok := parseErrorsCursor(cursor)
+	if !ok {
+		return nil, "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	projects, err := listProjects(ctx, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	withErrors := projectsWithErrors(projects)
+	start := slices.IndexFunc(withErrors, func(p listedProject) bool { return p.project.ID == projectID })
+	if start < 0 {
+		return nil, "", fmt.Errorf("project %s of the cursor no longer has open errors; list the resources again", projectID)
+	}
+	page := withErrors[start:min(start+errorProjectsPerPage, len(withErrors))]
+
+	// The projects of a page are listed concurrently, each with a single API call
+	errs := make([][]*bugsnagAPI.Error, len(page))
+	failures := make([]error, len(page))
+	var wg sync.WaitGroup
+	for i, p := range page {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i], failures[i] = listOpenErrors(ctx, cfg, p.project)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(failures...); err != nil {
+		return nil, "", err
+	}
+
+	var listed []mcp.Resource
+	for i, p := range page {
+		for _, e := range errs[i] {
+			listed = append(listed, newErrorResource(p.org, p.project, e))
+		}
+	}
+	var next mcp.Cursor
+	if end := start + len(page); end < len(withErrors) {
+		next = encodeErrorsCursor(withErrors[end].project.ID)
+	}
+	return listed, next, nil
+}
+
+// listedProject is a project listed as a resource, along with its organization.
+type listedProject struct {
+	org     *bugsnagAPI.Organization
+	project *bugsnagAPI.Project
+}
+
+// listProjects returns the projects of the current user with their organizations, ordered by organization and
+// project name, from the listing of the resolver.
+func listProjects(ctx context.Context, cfg *config.Config) ([]listedProject, error) {
+	orgs, err := cfg.Resolver(ctx).Organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := cfg.Resolver(ctx).Projects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orgsByID := make(map[string]*bugsnagAPI.Organization, len(orgs))
+	for _, org := range orgs {
+		orgsByID[org.ID] = org
+	}
+
+	listed := make([]listedProject, 0, len(projects))
+	for _, project := range projects {
+		if org, ok := orgsByID[project.OrganizationID]; ok {
+			listed = append(listed, listedProject{org: org, project: project})
+		}
+	}
+	slices.SortFunc(listed, func(a, b listedProject) int {
+		return cmp.Or(cmp.Compare(a.org.Name, b.org.Name), cmp.Compare(a.org.ID, b.org.ID), cmp.Compare(a.project.Name, b.project.Name))
+	})
+	return listed, nil
+}
+
+// projectsWithErrors returns the first maxErrorProjects projects with open errors, whose errors are listed.
+func projectsWithErrors(projects []listedProject) []listedProject {
+	var withErrors []listedProject
+	for _, p := range projects {
+		if p.project.OpenErrorCount > 0 && len(withErrors) < maxErrorProjects {
+			withErrors = append(withErrors, p)
+		}
+	}
+	return withErrors
+}
+
+// listOpenErrors returns the most recently seen open errors of a project.
+func listOpenErrors(ctx context.Context, cfg *config.Config, project *bugsnagAPI.Project) ([]*bugsnagAPI.Error, error) {
+	query := bugsnag.EncodeFilters([]bugsnagAPI.Filter{{Key: "error.status", Type: bugsnag.FilterTypeEqual, Value: "open"}})
+	query.Set("sort", "last_seen")
+	errs, _, err := bugsnag.ListPage[*bugsnagAPI.Error](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(project.ID)+"/errors", query, bugsnag.PageOptions{
+		PerPage: maxListedErrors,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the open errors of project %s: %v", project.Name, err)
+	}
+	return errs, nil
+}
+
+// errorsCursor is the content of the cursors of the pages listing open errors. The pages are built from end to
+// end by the hook listing them, which replaces whatever the server listed for the cursor.
+type errorsCursor struct {
+	// ErrorsFrom is the ID of the first project whose open errors are listed on the page.
+	ErrorsFrom string `json:"errors_from"`
+}
+
+// encodeErrorsCursor returns the cursor of the page listing open errors from the project with the given ID.
+// It is standard base64, the only cursor format the server accepts before calling the hooks.
+func encodeErrorsCursor(projectID string) mcp.Cursor {
+	raw, _ := json.Marshal(errorsCursor{ErrorsFrom: projectID})
+	return mcp.Cursor(base64.StdEncoding.EncodeToString(raw))
+}
+
+// parseErrorsCursor returns the ID of the first project of the page of open errors at cursor.
+func parseErrorsCursor(cursor mcp.Cursor) (string, bool) {
+	raw, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return "", false
+	}
+	var c errorsCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return "", false
+	}
+	return c.ErrorsFrom, c.ErrorsFrom != ""
+}
+
+// newProjectResource returns the concrete resource of a project, named after its organization and itself.
+// listsErrors says whether its open errors are listed too.
+func newProjectResource(org *bugsnagAPI.Organization, project *bugsnagAPI.Project, listsErrors bool) mcp.Resource {
+	description := fmt.Sprintf("Bugsnag project %s of %s, with %d open errors", project.Name, org.Name, project.OpenErrorCount)
+	if project.OpenErrorCount == 1 {
+		description = fmt.Sprintf("Bugsnag project %s of %s, with 1 open error", project.Name, org.Name)
+	}
+	if listsErrors && project.OpenErrorCount > maxListedErrors {
+		description += fmt.Sprintf(", the %d most recently seen of which are listed", maxListedErrors)
+	}
+	return mcp.NewResource(
+		"bugsnag://projects/"+url.PathEscape(project.ID),
+		org.Name+" / "+project.Name,
+		mcp.WithResourceDescription(description),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// newErrorResource returns the concrete resource of an open error, named after its project, class and message.
+func newErrorResource(org *bugsnagAPI.Organization, project *bugsnagAPI.Project, e *bugsnagAPI.Error) mcp.Resource {
+	message := strings.Join(strings.Fields(e.Message), " ")
+	if runes := []rune(message); len(runes) > maxNameMessage {
+		message = string(runes[:maxNameMessage-1]) + "…"
+	}
+	return mcp.NewResource(
+		"bugsnag://projects/"+url.PathEscape(project.ID)+"/errors/"+url.PathEscape(e.ID),
+		fmt.Sprintf("%s / %s: %s: %s", org.Name, project.Name, e.ErrorClass, message),
+		mcp.WithResourceDescription(fmt.Sprintf("Open %s in %s / %s: %d events, %d users, last seen %s",
+			cmp.Or(e.Severity, "error"), org.Name, project.Name, e.Events, e.Users, e.LastSeen.UTC().Format(time.RFC3339))),
+		mcp.WithMIMEType("application/json"),
+	)
+}
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
+)
+
+func TestListProjectResources(t *testing.T) {
+	cfg, fake := testconfig.NewFake(t)
+	ctx := context.Background()
+
+	projects, cursor, err := ListProjectResources(ctx, cfg, true)
+	if err != nil {
+		t.Fatalf("ListProjectResources() error = %v", err)
+	}
+	if got, want := resourceNames(projects), []string{"Acme / API", "Acme / Web", "Globex Corp / Android"}; !slices.Equal(got, want) {
+		t.Errorf("ListProjectResources() = %q, want %q", got, want)
+	}
+	if project := projects[0]; project.URI != "bugsnag://projects/"+bugsnagtest.APIProjectID || project.Description != "Bugsnag project API of Acme, with 2 open errors" {
+		t.Errorf("project resource = %+v, want the API project with its open errors", project)
+	}
+	if !IsErrorsCursor(cursor) {
+		t.Fatalf("ListProjectResources() cursor = %q, want the cursor of the open errors", cursor)
+	}
+
+	// The open errors of each project are listed with a single call
+	requests := len(fake.Requests())
+	listed, next, err := ListErrorResources(ctx, cfg, cursor)
+	if err != nil {
+		t.Fatalf("ListErrorResources() error = %v", err)
+	}
+	want := []string{
+		"Acme / API: runtime.Error: invalid memory address or nil pointer dereference",
+		"Acme / API: *pq.Error: deadlock detected",
+		"Acme / Web: TypeError: Cannot read properties of undefined (reading 'price')",
+		"Globex Corp / Android: java.lang.IllegalStateException: Fragment ProfileFragment not attached to a context.",
+	}
+	if got := resourceNames(listed); !slices.Equal(got, want) || next != "" {
+		t.Errorf("ListErrorResources() = %q, next %q, want %q on the last page", got, next, want)
+	}
+	if n := len(fake.Requests()) - requests; n != 3 {
+		t.Errorf("ListErrorResources() made %d requests, want one for each of the 3 projects", n)
+	}
+	if e := listed[0]; e.URI != "bugsnag://projects/"+bugsnagtest.APIProjectID+"/errors/"+bugsnagtest.NilPointerErrorID || !strings.HasPrefix(e.Description, "Open error in Acme / API: 120 events, 45 users") {
+		t.Errorf("error resource = %+v, want the nil pointer error with its impact", e)
+	}
+
+	if listed, cursor, err = ListProjectResources(ctx, cfg, false); err != nil || len(listed) != 3 || cursor != "" {
+		t.Errorf("ListProjectResources() without errors = %d resources, cursor %q, %v, want the 3 projects alone", len(listed), cursor, err)
+	}
+	if _, _, err := ListErrorResources(ctx, cfg, encodeErrorsCursor("6a0a1b2c3d4e5f6a7b8c9dff")); err == nil {
+		t.Error("ListErrorResources() of an unknown project error = nil, want an error")
+	}
+	if IsErrorsCursor("bm90IG91cnM=") {
+		t.Error("IsErrorsCursor() of another cursor = true, want false")
+	}
+
+	fake.Fail(bugsnagtest.Fault{Path: "/projects/" + bugsnagtest.WebProjectID + "/errors", Status: http.StatusInternalServerError, Message: "Internal error"})
+	if _, _, err := ListErrorResources(bugsnag.WithCacheBypass(ctx), cfg, encodeErrorsCursor(bugsnagtest.APIProjectID)); err == nil || !strings.Contains(err.Error(), "Internal error") {
+		t.Errorf("ListErrorResources() error = %v, want the API failure", err)
+	}
+}
+
+// resourceNames returns the name of each resource.
+func resourceNames(listed []mcp.Resource) []string {
+	var names []string
+	for _, resource := range listed {
+		names = append(names, resource.Name)
+	}
+	return names
+}
+
+func TestNewErrorResourceName(t *testing.T) {
+	org := &bugsnagAPI.Organization{Name: "Acme"}
+	project := &bugsnagAPI.Project{ID: "p1", Name: "API"}
+	e := &bugsnagAPI.Error{ID: "e1", ErrorClass: "Error", Message: "line one\n\tline two " + strings.Repeat("é", 100)}
+
+	resource := newErrorResource(org, project, e)
+	message := strings.TrimPrefix(resource.Name, "Acme / API: Error: ")
+	if !strings.HasPrefix(message, "line one line two ") || utf8.RuneCountInString(message) != maxNameMessage || !strings.HasSuffix(message, "…") {
+		t.Errorf("newErrorResource() name = %q, want the message on one line, truncated to %d characters", resource.Name, maxNameMessage)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
 	"github.com/sazap10/bugsnag-mcp/pkg/config"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -16,8 +18,19 @@ const (
 	OrganizationResourceURI = "bugsnag://organizations"
 	ProjectTemplateURI      = "bugsnag://projects/{id}"
 	EventTemplateURI        = "bugsnag://projects/{project_id}/events/{id}"
+	ErrorTemplateURI        = "bugsnag://projects/{project_id}/errors/{id}"
 )
 
+// LabelSeparator separates an ID in a resource URI from a readable label following it, e.g. the slug in
+// bugsnag://projects/{id}~web, as suggested by argument completion. The label is ignored.
+const LabelSeparator = "~"
+
+// TrimLabel returns the ID of a URI segment without its label, if any.
+func TrimLabel(segment string) string {
+	id, _, _ := strings.Cut(segment, LabelSeparator)
+	return id
+}
+
 // NewOrganizationResource returns the MCP resource for listing Bugsnag organizations.
 func NewOrganizationResource() mcp.Resource {
 	return mcp.NewResource(
@@ -32,7 +45,9 @@ func NewOrganizationResource() mcp.Resource {
 func HandleOrganizationResource(cfg *config.Config) server.ResourceHandlerFunc {
 	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 		// Call the Bugsnag API to get the list of organizations
-		orgs, _, err := cfg.APIClient.CurrentUser.ListOrganizations(ctx, nil)
+		orgs, _, err := bugsnag.ListPage[*bugsnagAPI.Organization](ctx, cfg.Client(ctx), "user/organizations", nil, bugsnag.PageOptions{
+			MaxItems: bugsnag.MaxItemsLimit,
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -80,7 +95,7 @@ func HandleProjectResource(cfg *config.Config) server.ResourceTemplateHandlerFun
 		}
 
 		// Call the Bugsnag API to get the project details
-		project, _, err := cfg.APIClient.Projects.GetProject(ctx, projectID)
+		project, _, err := cfg.Client(ctx).Projects.GetProject(ctx, projectID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve project: %v", err)
 		}
@@ -105,7 +120,7 @@ func NewEventResource() mcp.ResourceTemplate {
 	return mcp.NewResourceTemplate(
 		EventTemplateURI,
 		"Bugsnag Event",
-		mcp.WithTemplateDescription("Retrieves a Bugsnag event by ID"),
+		mcp.WithTemplateDescription("Retrieves a Bugsnag event by ID, with its stack traces rendered as Markdown"),
 		mcp.WithTemplateMIMEType("application/json"),
 	)
 }
@@ -132,7 +147,7 @@ func HandleEventResource(cfg *config.Config) server.ResourceTemplateHandlerFunc
 		}
 
 		// Call the Bugsnag API to get the event details
-		event, _, err := cfg.APIClient.Events.GetEvent(ctx, projectID, eventID)
+		event, _, err := cfg.Client(ctx).Events.GetEvent(ctx, projectID, eventID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve event: %v", err)
 		}
@@ -142,25 +157,102 @@ func HandleEventResource(cfg *config.Config) server.ResourceTemplateHandlerFunc
 			return nil, fmt.Errorf("failed to marshal event: %v", err)
 		}
 
-		return []mcp.ResourceContents{
+		contents := []mcp.ResourceContents{
 			mcp.TextResourceContents{
 				URI:      uri,
 				MIMEType: "application/json",
 				Text:     string(eventJSON),
 			},
+		}
+		if stacktraces := formatStacktraces(event); stacktraces != "" {
+			contents = append(contents, mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: "text/markdown",
+				Text:     stacktraces,
+			})
+		}
+		return contents, nil
+	}
+}
+
+// formatStacktraces renders the processed stack trace of each exception of an event, or "" if it has none.
+func formatStacktraces(event *bugsnagAPI.Event) string {
+	var b strings.Builder
+	for _, exception := range event.Exceptions {
+		if len(exception.Stacktrace) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s: %s\n", exception.ErrorClass, exception.Message)
+		b.WriteString(bugsnag.FormatStacktrace(bugsnag.ProcessStacktrace(exception.Stacktrace), bugsnag.TraceFormatOptions{AllSnippets: true}))
+	}
+	return b.String()
+}
+
+// NewErrorResource returns the MCP resource template for a single Bugsnag error by project and error ID.
+func NewErrorResource() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		ErrorTemplateURI,
+		"Bugsnag Error",
+		mcp.WithTemplateDescription("Retrieves a Bugsnag error by ID"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// HandleErrorResource handles requests to retrieve a specific error by project and error ID from Bugsnag.
+func HandleErrorResource(cfg *config.Config) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		// Extract the error ID from the request
+		uri := req.Params.URI
+		if uri == "" {
+			return nil, fmt.Errorf("error ID not provided in request")
+		}
+		ids, err := extractIDsFromURI(uri, "projects", "errors")
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract IDs from URI: %v", err)
+		}
+		projectID, ok := ids["projects"]
+		if !ok {
+			return nil, fmt.Errorf("project ID not found in URI: %s", uri)
+		}
+		errorID, ok := ids["errors"]
+		if !ok {
+			return nil, fmt.Errorf("error ID not found in URI: %s", uri)
+		}
+
+		// Call the Bugsnag API to get the error details
+		bugsnagErr, _, err := cfg.Client(ctx).Errors.GetError(ctx, projectID, errorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve error: %v", err)
+		}
+
+		errJSON, err := json.Marshal(bugsnagErr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal error: %v", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(errJSON),
+			},
 		}, nil
 	}
 }
 
 // extractIDsFromURI extracts IDs from a URI given a list of segment names (e.g., "projects", "events").
-// Returns a map of segment name to ID, e.g. {"projects": "123", "events": "456"}.
+// Returns a map of segment name to ID, e.g. {"projects": "123", "events": "456"}. Labels following the IDs
+// are dropped.
 func extractIDsFromURI(uri string, segments ...string) (map[string]string, error) {
 	result := make(map[string]string)
 	parts := strings.Split(uri, "/")
 	for i, part := range parts {
 		for _, seg := range segments {
 			if part == seg && i+1 < len(parts) {
-				id := parts[i+1]
+				id := TrimLabel(parts[i+1])
 				if id != "" {
 					result[seg] = id
 				}
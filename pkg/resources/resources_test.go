@@ -1,8 +1,17 @@
 package resources
 
 import (
+	"context"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
 )
 
 func TestExtractIDsFromURI(t *testing.T) {
@@ -27,6 +36,13 @@ func TestExtractIDsFromURI(t *testing.T) {
 			want:     map[string]string{"projects": "123", "events": "456"},
 			wantErr:  false,
 		},
+		{
+			name:     "project and error segments found",
+			uri:      "bugsnag://projects/123/errors/789",
+			segments: []string{"projects", "errors"},
+			want:     map[string]string{"projects": "123", "errors": "789"},
+			wantErr:  false,
+		},
 		{
 			name:     "segment not found",
 			uri:      "bugsnag://organizations/789",
@@ -76,6 +92,20 @@ func TestExtractIDsFromURI(t *testing.T) {
 			want:     map[string]string{"projects": "123"},
 			wantErr:  false,
 		},
+		{
+			name:     "labelled ids",
+			uri:      "bugsnag://projects/123~api-server/events/456",
+			segments: []string{"projects", "events"},
+			want:     map[string]string{"projects": "123", "events": "456"},
+			wantErr:  false,
+		},
+		{
+			name:     "label without id",
+			uri:      "bugsnag://projects/~api-server",
+			segments: []string{"projects"},
+			want:     nil,
+			wantErr:  true,
+		},
 		{
 			name:     "multiple segments, one missing id",
 			uri:      "bugsnag://projects/123/events",
@@ -98,3 +128,94 @@ func TestExtractIDsFromURI(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatStacktraces(t *testing.T) {
+	event := &bugsnagAPI.Event{Exceptions: []bugsnagAPI.Exceptions{
+		{ErrorClass: "RuntimeError", Message: "boom", Stacktrace: []bugsnagAPI.Stacktrace{
+			{Method: "handle", File: "app/users.rb", LineNumber: 3, InProject: true},
+			{Method: "call", File: "vendor/rack/lib/rack.rb", LineNumber: 10},
+			{Method: "run", File: "vendor/puma/lib/puma.rb", LineNumber: 20},
+		}},
+		{ErrorClass: "IOError", Message: "closed"},
+	}}
+
+	want := "## RuntimeError: boom\n- **app/users.rb:3** `handle`\n- _2 library frames (rack, puma)_\n"
+	if got := formatStacktraces(event); got != want {
+		t.Errorf("formatStacktraces() =\n%s\nwant\n%s", got, want)
+	}
+	if got := formatStacktraces(&bugsnagAPI.Event{}); got != "" {
+		t.Errorf("formatStacktraces() without exceptions = %q, want empty", got)
+	}
+}
+
+func TestResourceHandlers(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+
+	tests := []struct {
+		name    string
+		handler server.ResourceTemplateHandlerFunc
+		uri     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "organizations",
+			handler: server.ResourceTemplateHandlerFunc(HandleOrganizationResource(cfg)),
+			uri:     OrganizationResourceURI,
+			want:    []string{`"slug":"acme"`, `"slug":"globex"`},
+		},
+		{
+			name:    "project",
+			handler: HandleProjectResource(cfg),
+			uri:     "bugsnag://projects/" + bugsnagtest.WebProjectID,
+			want:    []string{`"name":"Web"`},
+		},
+		{
+			name:    "event with its stack trace",
+			handler: HandleEventResource(cfg),
+			uri:     "bugsnag://projects/" + bugsnagtest.APIProjectID + "/events/" + bugsnagtest.NilPointerEventID,
+			want:    []string{`"error_id":"` + bugsnagtest.NilPointerErrorID + `"`, "- **internal/users/handler.go:31**"},
+		},
+		{
+			name:    "project labelled by completion",
+			handler: HandleProjectResource(cfg),
+			uri:     "bugsnag://projects/" + bugsnagtest.WebProjectID + "~web",
+			want:    []string{`"name":"Web"`},
+		},
+		{
+			name:    "error",
+			handler: HandleErrorResource(cfg),
+			uri:     "bugsnag://projects/" + bugsnagtest.APIProjectID + "/errors/" + bugsnagtest.DeadlineErrorID,
+			want:    []string{`"status":"fixed"`},
+		},
+		{
+			name:    "unknown error",
+			handler: HandleErrorResource(cfg),
+			uri:     "bugsnag://projects/" + bugsnagtest.WebProjectID + "/errors/" + bugsnagtest.DeadlineErrorID,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.ReadResourceRequest{}
+			req.Params.URI = tt.uri
+			contents, err := tt.handler(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read resource error = %v, want error %t", err, tt.wantErr)
+			}
+			var texts []string
+			for _, content := range contents {
+				if text, ok := content.(mcp.TextResourceContents); ok {
+					texts = append(texts, text.Text)
+				}
+			}
+			got := strings.Join(texts, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("resource contents do not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+	"github.com/sazap10/bugsnag-mcp/pkg/prompts"
+	"github.com/sazap10/bugsnag-mcp/pkg/resources"
+	"github.com/sazap10/bugsnag-mcp/pkg/tools"
+)
+
+const (
+	// maxCompletionValues is the maximum number of values in a completion, set by the MCP specification.
+	maxCompletionValues = 100
+	// recentCompletionItems is the number of recent errors or events completions are picked from.
+	recentCompletionItems = 20
+)
+
+// completionKind is the kind of Bugsnag entity an argument refers to.
+type completionKind int
+
+const (
+	completeOrganization completionKind = iota + 1
+	completeProject
+	completeError
+	completeEvent
+)
+
+// resourceCompletions are the kinds of entity the arguments of each resource template refer to.
+var resourceCompletions = map[string]map[string]completionKind{
+	resources.ProjectTemplateURI: {"id": completeProject},
+	resources.EventTemplateURI:   {"project_id": completeProject, "id": completeEvent},
+	resources.ErrorTemplateURI:   {"project_id": completeProject, "id": completeError},
+}
+
+// promptCompletions are the kinds of entity the arguments of each prompt refer to.
+var promptCompletions = map[string]map[string]completionKind{
+	prompts.TriageErrorPromptID:       {"project": completeProject, "error": completeError},
+	prompts.InvestigateSpikePromptID:  {"project": completeProject},
+	prompts.ReleaseHealthPromptID:     {"project": completeProject},
+	prompts.WeeklyErrorReportPromptID: {"org": completeOrganization},
+}
+
+// completionStyle is how suggestions refer to organizations and projects.
+type completionStyle int
+
+const (
+	// styleLabelledID suggests IDs followed by their slug, e.g. {id}~web, for resource templates, whose
+	// handlers take IDs and drop the label.
+	styleLabelledID completionStyle = iota + 1
+	// styleSlug suggests organization slugs and organization/project slugs, e.g. acme/web, for prompts,
+	// which resolve them like the tools.
+	styleSlug
+)
+
+// completionProvider suggests organizations, projects, and recent errors and events for the arguments of
+// resource templates and prompts. Organizations and projects are matched by ID prefix, or by name or slug,
+// so users can type the name of a project to get a value referring to it. Errors and events are matched by
+// ID prefix among the most recent ones of the project already filled in.
+type completionProvider struct {
+	cfg *config.Config
+}
+
+// CompleteResourceArgument suggests values for an argument of a resource template.
+func (p *completionProvider) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, args mcp.CompleteContext) (*mcp.Completion, error) {
+	return p.complete(ctx, resourceCompletions[uri][argument.Name], styleLabelledID, argument.Value, args.Arguments["project_id"])
+}
+
+// CompletePromptArgument suggests values for an argument of a prompt.
+func (p *completionProvider) CompletePromptArgument(ctx context.Context, promptName string, argument mcp.CompleteArgument, args mcp.CompleteContext) (*mcp.Completion, error) {
+	return p.complete(ctx, promptCompletions[promptName][argument.Name], styleSlug, argument.Value, args.Arguments["project"])
+}
+
+// complete suggests the entities of the given kind matching value, referred to in the given style. Errors and
+// events are those of the project referred to by projectRef. Nothing is suggested for data hidden by
+// disabling its tool.
+func (p *completionProvider) complete(ctx context.Context, kind completionKind, style completionStyle, value, projectRef string) (*mcp.Completion, error) {
+	value = resources.TrimLabel(strings.TrimSpace(value))
+	projectRef = resources.TrimLabel(projectRef)
+	switch {
+	case kind == completeOrganization && p.enabled(tools.GetUserOrganizationsToolID):
+		orgs, err := p.cfg.Resolver(ctx).Organizations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return newCompletion(matchEntities(orgs, value, func(o *bugsnagAPI.Organization) (string, string, string) {
+			return o.ID, o.Name, o.Slug
+		}, func(o *bugsnagAPI.Organization) string {
+			return completionValue(style, o.ID, o.Slug)
+		}), false), nil
+	case kind == completeProject && p.enabled(tools.GetUserProjectsToolID):
+		orgs, err := p.cfg.Resolver(ctx).Organizations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		projects, err := p.cfg.Resolver(ctx).Projects(ctx)
+		if err != nil {
+			return nil, err
+		}
+		orgSlugs := make(map[string]string, len(orgs))
+		for _, o := range orgs {
+			orgSlugs[o.ID] = o.Slug
+		}
+		// Projects are matched by organization/project slugs too, so typing acme/ narrows them to Acme's
+		return newCompletion(matchEntities(projects, value, func(project *bugsnagAPI.Project) (string, string, string) {
+			return project.ID, project.Name, orgSlugs[project.OrganizationID] + "/" + project.Slug
+		}, func(project *bugsnagAPI.Project) string {
+			if style == styleSlug {
+				return orgSlugs[project.OrganizationID] + "/" + project.Slug
+			}
+			return completionValue(style, project.ID, project.Slug)
+		}), false), nil
+	case kind == completeError && p.enabled(tools.ListProjectErrorsToolID):
+		return p.completeRecent(ctx, projectRef, "errors", value, func(ctx context.Context, path string) ([]string, *bugsnag.PageInfo, error) {
+			errs, page, err := bugsnag.ListPage[*bugsnagAPI.Error](ctx, p.cfg.Client(ctx), path, url.Values{"sort": {"last_seen"}}, bugsnag.PageOptions{PerPage: recentCompletionItems})
+			return collectIDs(errs, func(e *bugsnagAPI.Error) string { return e.ID }), page, err
+		})
+	case kind == completeEvent && p.enabled(tools.GetProjectEventsToolID):
+		return p.completeRecent(ctx, projectRef, "events", value, func(ctx context.Context, path string) ([]string, *bugsnag.PageInfo, error) {
+			events, page, err := bugsnag.ListPage[*bugsnagAPI.Event](ctx, p.cfg.Client(ctx), path, nil, bugsnag.PageOptions{PerPage: recentCompletionItems})
+			return collectIDs(events, func(e *bugsnagAPI.Event) string { return e.ID }), page, err
+		})
+	default:
+		return newCompletion(nil, false), nil
+	}
+}
+
+// completeRecent suggests the IDs starting with value among the most recent errors or events of a project,
+// listed by list. Nothing is suggested until the project is filled in and can be resolved.
+func (p *completionProvider) completeRecent(ctx context.Context, projectRef, collection, value string, list func(context.Context, string) ([]string, *bugsnag.PageInfo, error)) (*mcp.Completion, error) {
+	if strings.TrimSpace(projectRef) == "" {
+		return newCompletion(nil, false), nil
+	}
+	projectID, err := p.cfg.Resolver(ctx).ResolveProject(ctx, projectRef)
+	if err != nil {
+		return newCompletion(nil, false), nil
+	}
+	ids, page, err := list(ctx, "projects/"+url.PathEscape(projectID)+"/"+collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s: %v", collection, err)
+	}
+	var matches []string
+	for _, id := range ids {
+		if strings.HasPrefix(id, strings.ToLower(value)) {
+			matches = append(matches, id)
+		}
+	}
+	return newCompletion(matches, page.NextCursor != ""), nil
+}
+
+// enabled reports whether the tool with the given ID is enabled, and so whether its data can be suggested.
+func (p *completionProvider) enabled(toolID string) bool {
+	return p.cfg.ToolEnabled(toolID, tools.Mutates(toolID))
+}
+
+// completionValue returns how a completion in the given style refers to the entity with an ID and slug.
+func completionValue(style completionStyle, id, slug string) string {
+	if style == styleSlug {
+		return slug
+	}
+	return id + resources.LabelSeparator + slug
+}
+
+// matchEntities returns the values of the entities whose ID starts with value, or whose name or slug contains
+// it ignoring case, ordered by name.
+func matchEntities[T any](entities []T, value string, fields func(T) (id, name, slug string), valueOf func(T) string) []string {
+	type match struct{ id, name, value string }
+	lower := strings.ToLower(value)
+	var matches []match
+	for _, e := range entities {
+		id, name, slug := fields(e)
+		if strings.HasPrefix(id, lower) || strings.Contains(strings.ToLower(name), lower) || strings.Contains(strings.ToLower(slug), lower) {
+			matches = append(matches, match{id: id, name: name, value: valueOf(e)})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.name), strings.ToLower(b.name)), cmp.Compare(a.id, b.id))
+	})
+	values := make([]string, 0, len(matches))
+	for _, m := range matches {
+		values = append(values, m.value)
+	}
+	return values
+}
+
+// collectIDs returns the ID of each item.
+func collectIDs[T any](items []T, id func(T) string) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, id(item))
+	}
+	return ids
+}
+
+// newCompletion returns the completion of values, truncated to the maximum allowed. hasMore reports that
+// values beyond those are available, in which case their total is unknown.
+func newCompletion(values []string, hasMore bool) *mcp.Completion {
+	completion := &mcp.Completion{Values: []string{}, HasMore: hasMore}
+	if !hasMore {
+		completion.Total = len(values)
+	}
+	if len(values) > maxCompletionValues {
+		values, completion.HasMore = values[:maxCompletionValues], true
+	}
+	completion.Values = append(completion.Values, values...)
+	return completion
+}
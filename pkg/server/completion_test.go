@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
+	"github.com/sazap10/bugsnag-mcp/pkg/prompts"
+	"github.com/sazap10/bugsnag-mcp/pkg/resources"
+	"github.com/sazap10/bugsnag-mcp/pkg/tools"
+)
+
+func TestCompletionProvider(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	cfg.DeniedTools = []string{tools.GetProjectEventsToolID}
+	provider := &completionProvider{cfg: cfg}
+
+	tests := []struct {
+		name     string
+		resource string
+		prompt   string
+		argument string
+		value    string
+		context  map[string]string
+		want     []string
+		wantNot  []string
+	}{
+		{name: "project by name", resource: resources.ProjectTemplateURI, argument: "id", value: "AP", want: []string{bugsnagtest.APIProjectID + "~api"}, wantNot: []string{bugsnagtest.WebProjectID + "~web"}},
+		{name: "project by ID prefix", resource: resources.ErrorTemplateURI, argument: "project_id", value: bugsnagtest.WebProjectID[:20], want: []string{bugsnagtest.WebProjectID + "~web"}},
+		{name: "labelled project", resource: resources.ProjectTemplateURI, argument: "id", value: bugsnagtest.WebProjectID + "~w", want: []string{bugsnagtest.WebProjectID + "~web"}},
+		{name: "all projects", prompt: prompts.InvestigateSpikePromptID, argument: "project", want: []string{"acme/web", "acme/api", "globex/android"}},
+		{name: "projects of an organization", prompt: prompts.ReleaseHealthPromptID, argument: "project", value: "acme/", want: []string{"acme/web", "acme/api"}, wantNot: []string{"globex/android"}},
+		{name: "recent errors", resource: resources.ErrorTemplateURI, argument: "id", context: map[string]string{"project_id": bugsnagtest.APIProjectID}, want: []string{bugsnagtest.NilPointerErrorID, bugsnagtest.DeadlineErrorID}},
+		{name: "recent errors of a labelled project", resource: resources.ErrorTemplateURI, argument: "id", context: map[string]string{"project_id": bugsnagtest.APIProjectID + "~api"}, want: []string{bugsnagtest.NilPointerErrorID, bugsnagtest.DeadlineErrorID}},
+		{name: "recent errors by ID", prompt: prompts.TriageErrorPromptID, argument: "error", value: bugsnagtest.NilPointerErrorID, context: map[string]string{"project": "acme/api"}, want: []string{bugsnagtest.NilPointerErrorID}, wantNot: []string{bugsnagtest.DeadlineErrorID}},
+		{name: "errors before the project", prompt: prompts.TriageErrorPromptID, argument: "error"},
+		{name: "errors of an unknown project", resource: resources.ErrorTemplateURI, argument: "id", context: map[string]string{"project_id": "acme/billing"}},
+		{name: "events of a disabled tool", resource: resources.EventTemplateURI, argument: "id", context: map[string]string{"project_id": bugsnagtest.APIProjectID}, wantNot: []string{bugsnagtest.NilPointerEventID}},
+		{name: "organization", prompt: prompts.WeeklyErrorReportPromptID, argument: "org", value: "glob", want: []string{"globex"}, wantNot: []string{"acme"}},
+		{name: "unknown argument", prompt: prompts.ReleaseHealthPromptID, argument: "version", value: "1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argument := mcp.CompleteArgument{Name: tt.argument, Value: tt.value}
+			args := mcp.CompleteContext{Arguments: tt.context}
+			var completion *mcp.Completion
+			var err error
+			if tt.resource != "" {
+				completion, err = provider.CompleteResourceArgument(context.Background(), tt.resource, argument, args)
+			} else {
+				completion, err = provider.CompletePromptArgument(context.Background(), tt.prompt, argument, args)
+			}
+			if err != nil {
+				t.Fatalf("completion error = %v", err)
+			}
+			if tt.want == nil && len(completion.Values) > 0 {
+				t.Errorf("completion = %v, want none", completion.Values)
+			}
+			for _, want := range tt.want {
+				if !slices.Contains(completion.Values, want) {
+					t.Errorf("completion = %v, want %s", completion.Values, want)
+				}
+			}
+			for _, unwanted := range tt.wantNot {
+				if slices.Contains(completion.Values, unwanted) {
+					t.Errorf("completion = %v, want %s left out", completion.Values, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCompletion(t *testing.T) {
+	var values []string
+	for i := range 150 {
+		values = append(values, strconv.Itoa(i))
+	}
+
+	tests := []struct {
+		name        string
+		values      []string
+		hasMore     bool
+		wantValues  int
+		wantTotal   int
+		wantHasMore bool
+	}{
+		{name: "none", wantValues: 0},
+		{name: "all", values: values[:3], wantValues: 3, wantTotal: 3},
+		{name: "truncated", values: values, wantValues: maxCompletionValues, wantTotal: 150, wantHasMore: true},
+		{name: "more available", values: values[:3], hasMore: true, wantValues: 3, wantHasMore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newCompletion(tt.values, tt.hasMore)
+			if got.Values == nil || len(got.Values) != tt.wantValues || got.Total != tt.wantTotal || got.HasMore != tt.wantHasMore {
+				t.Errorf("newCompletion() = %d values, total %d, has more %t, want %d, %d, %t",
+					len(got.Values), got.Total, got.HasMore, tt.wantValues, tt.wantTotal, tt.wantHasMore)
+			}
+		})
+	}
+}
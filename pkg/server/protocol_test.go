@@ -0,0 +1,445 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// mcpSession is a client session with the MCP server over one transport, sending raw JSON-RPC messages.
+type mcpSession struct {
+	transport transport.Interface
+	nextID    int64
+}
+
+// call sends a request and returns its response, failing the test if it could not be sent.
+func (s *mcpSession) call(t *testing.T, method string, params any) *transport.JSONRPCResponse {
+	t.Helper()
+	s.nextID++
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := s.transport.SendRequest(ctx, transport.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(s.nextID),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		t.Fatalf("%s request error = %v", method, err)
+	}
+	return resp
+}
+
+// result sends a request and decodes its result into v, failing the test on a JSON-RPC error.
+func (s *mcpSession) result(t *testing.T, method string, params any, v any) {
+	t.Helper()
+	resp := s.call(t, method, params)
+	if resp.Error != nil {
+		t.Fatalf("%s error = %d %s, want a result", method, resp.Error.Code, resp.Error.Message)
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		t.Fatalf("%s result %s cannot be decoded: %v", method, resp.Result, err)
+	}
+}
+
+// callTool calls a tool and returns its result, failing the test on a JSON-RPC error.
+func (s *mcpSession) callTool(t *testing.T, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	resp := s.call(t, "tools/call", mcp.CallToolParams{Name: name, Arguments: args})
+	if resp.Error != nil {
+		t.Fatalf("tools/call %s error = %d %s, want a result", name, resp.Error.Code, resp.Error.Message)
+	}
+	result, err := mcp.ParseCallToolResult(&resp.Result)
+	if err != nil {
+		t.Fatalf("tools/call %s result %s cannot be decoded: %v", name, resp.Result, err)
+	}
+	return result
+}
+
+// reserveAddr returns a free local address for a server to listen on.
+func reserveAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// waitForServer waits until addr accepts connections.
+func waitForServer(t *testing.T, addr string) {
+	t.Helper()
+	for range 100 {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server did not start listening on %s", addr)
+}
+
+// connect serves server over the named transport, and returns a client session with it.
+// The server and session are shut down at the end of the test.
+func connect(t *testing.T, name string, server *Server) *mcpSession {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	var tr transport.Interface
+	switch name {
+	case "stdio":
+		serverIn, clientOut := io.Pipe()
+		clientIn, serverOut := io.Pipe()
+		go func() {
+			done <- serveStdio(ctx, server, serverIn, serverOut)
+			serverOut.Close()
+		}()
+		tr = transport.NewIO(clientIn, clientOut, io.NopCloser(strings.NewReader("")))
+	case "sse":
+		addr := reserveAddr(t)
+		go func() { done <- ServeSSE(ctx, server, addr, SSEOptions{DrainTimeout: time.Second}) }()
+		waitForServer(t, addr)
+		sse, err := transport.NewSSE("http://" + addr + "/sse")
+		if err != nil {
+			t.Fatalf("transport.NewSSE() error = %v", err)
+		}
+		tr = sse
+	case "http":
+		addr := reserveAddr(t)
+		go func() {
+			done <- ServeHTTP(ctx, server, addr, HTTPOptions{EndpointPath: "/mcp", DrainTimeout: time.Second})
+		}()
+		waitForServer(t, addr)
+		streamable, err := transport.NewStreamableHTTP("http://" + addr + "/mcp")
+		if err != nil {
+			t.Fatalf("transport.NewStreamableHTTP() error = %v", err)
+		}
+		tr = streamable
+	default:
+		t.Fatalf("unknown transport %s", name)
+	}
+
+	if err := tr.Start(ctx); err != nil {
+		t.Fatalf("starting the %s transport error = %v", name, err)
+	}
+	t.Cleanup(func() {
+		tr.Close()
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("serving over %s error = %v", name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("serving over %s did not stop", name)
+		}
+	})
+	return &mcpSession{transport: tr}
+}
+
+// assertGolden compares got with the golden file testdata/name, rewriting it with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing %s error = %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s error = %v, run the tests with -update to create it", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed, run the tests with -update if it is expected:\n%s", path, got)
+	}
+}
+
+// newTestServer returns the MCP server with every tool enabled, using a fake Bugsnag API.
+func newTestServer(t *testing.T) (*Server, *bugsnagtest.Server) {
+	t.Helper()
+	fake := bugsnagtest.NewServer(bugsnagtest.DefaultFixtures())
+	t.Cleanup(fake.Close)
+	return NewMCPServer("bugsnag-mcp", "test", testconfig.New(t, fake)), fake
+}
+
+func TestMCPProtocol(t *testing.T) {
+	for _, name := range []string{"stdio", "sse", "http"} {
+		t.Run(name, func(t *testing.T) {
+			server, fake := newTestServer(t)
+			session := connect(t, name, server)
+
+			t.Run("initialize", func(t *testing.T) {
+				var result mcp.InitializeResult
+				session.result(t, "initialize", mcp.InitializeParams{
+					ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+					ClientInfo:      mcp.Implementation{Name: "protocol-test", Version: "1.0.0"},
+				}, &result)
+				if result.ServerInfo.Name != "bugsnag-mcp" || result.ProtocolVersion != mcp.LATEST_PROTOCOL_VERSION {
+					t.Errorf("initialize = %+v, want the server info and protocol version", result)
+				}
+				if result.Capabilities.Tools == nil || result.Capabilities.Resources == nil || result.Capabilities.Prompts == nil || result.Capabilities.Completions == nil {
+					t.Errorf("capabilities = %+v, want tools, resources, prompts and completions", result.Capabilities)
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := session.transport.SendNotification(ctx, mcp.JSONRPCNotification{
+					JSONRPC:      mcp.JSONRPC_VERSION,
+					Notification: mcp.Notification{Method: "notifications/initialized"},
+				}); err != nil {
+					t.Fatalf("notifications/initialized error = %v", err)
+				}
+			})
+
+			t.Run("tools/list", func(t *testing.T) {
+				resp := session.call(t, "tools/list", nil)
+				if resp.Error != nil {
+					t.Fatalf("tools/list error = %s", resp.Error.Message)
+				}
+				var indented bytes.Buffer
+				if err := json.Indent(&indented, resp.Result, "", "  "); err != nil {
+					t.Fatalf("tools/list result %s is not JSON: %v", resp.Result, err)
+				}
+				indented.WriteString("\n")
+				assertGolden(t, "tools.golden.json", indented.Bytes())
+			})
+
+			t.Run("tools/call", func(t *testing.T) {
+				result := session.callTool(t, "get_project_event", map[string]any{"project_id": "acme/api", "event_id": bugsnagtest.NilPointerEventID})
+				if result.IsError || len(result.Content) == 0 {
+					t.Fatalf("get_project_event = %+v, want the event", result)
+				}
+				if text, ok := result.Content[0].(mcp.TextContent); !ok || !strings.Contains(text.Text, "## runtime.Error") {
+					t.Errorf("get_project_event content = %+v, want the event summary", result.Content[0])
+				}
+			})
+
+			t.Run("tools/call with invalid arguments", func(t *testing.T) {
+				result := session.callTool(t, "get_project_event", map[string]any{"project_id": "acme/api"})
+				if text, ok := result.Content[0].(mcp.TextContent); !result.IsError || !ok || !strings.Contains(text.Text, "event_id") {
+					t.Errorf("get_project_event without event_id = %+v, want an error result", result)
+				}
+			})
+
+			t.Run("tools/call with a failing API", func(t *testing.T) {
+				fake.Fail(bugsnagtest.Fault{Path: "/projects/" + bugsnagtest.WebProjectID + "/errors", Status: http.StatusInternalServerError, Message: "Bugsnag is down", Times: 1})
+				result := session.callTool(t, "list_project_errors", map[string]any{"project_id": bugsnagtest.WebProjectID})
+				if text, ok := result.Content[0].(mcp.TextContent); !result.IsError || !ok || !strings.Contains(text.Text, "Bugsnag is down") {
+					t.Errorf("list_project_errors = %+v, want the API failure", result)
+				}
+			})
+
+			t.Run("tools/call of an unknown tool", func(t *testing.T) {
+				resp := session.call(t, "tools/call", mcp.CallToolParams{Name: "delete_everything"})
+				if resp.Error == nil || !strings.Contains(resp.Error.Message, "delete_everything") {
+					t.Errorf("tools/call of an unknown tool = %s, want a JSON-RPC error", resp.Result)
+				}
+			})
+
+			t.Run("resources/list", func(t *testing.T) {
+				var result mcp.ListResourcesResult
+				session.result(t, "resources/list", nil, &result)
+				var uris []string
+				for _, resource := range result.Resources {
+					uris = append(uris, resource.URI)
+				}
+				want := []string{
+					"bugsnag://organizations",
+					"bugsnag://projects/" + bugsnagtest.APIProjectID,
+				}
+				if len(uris) < len(want) || !slices.Equal(uris[:len(want)], want) || result.NextCursor == "" {
+					t.Errorf("resources/list = %v, cursor %q, want the organizations, then the projects and a cursor", uris, result.NextCursor)
+				}
+
+				// The open errors are on the next page
+				session.result(t, "resources/list", map[string]any{"cursor": result.NextCursor}, &result)
+				uris = nil
+				for _, resource := range result.Resources {
+					uris = append(uris, resource.URI)
+				}
+				if want := "bugsnag://projects/" + bugsnagtest.APIProjectID + "/errors/" + bugsnagtest.NilPointerErrorID; len(uris) == 0 || uris[0] != want {
+					t.Errorf("resources/list = %v, want the open errors of the projects, starting with %s", uris, want)
+				}
+				if slices.Contains(uris, "bugsnag://projects/"+bugsnagtest.APIProjectID+"/errors/"+bugsnagtest.DeadlineErrorID) {
+					t.Errorf("resources/list = %v, want only the open errors", uris)
+				}
+			})
+
+			t.Run("resources/templates/list", func(t *testing.T) {
+				var result mcp.ListResourceTemplatesResult
+				session.result(t, "resources/templates/list", nil, &result)
+				var templates []string
+				for _, template := range result.ResourceTemplates {
+					templates = append(templates, template.URITemplate.Raw())
+				}
+				want := "bugsnag://projects/{project_id}/errors/{id} bugsnag://projects/{project_id}/events/{id} bugsnag://projects/{id}"
+				if got := strings.Join(templates, " "); got != want {
+					t.Errorf("resources/templates/list = %s, want %s", got, want)
+				}
+			})
+
+			t.Run("resources/read", func(t *testing.T) {
+				var result struct {
+					Contents []mcp.TextResourceContents `json:"contents"`
+				}
+				uri := "bugsnag://projects/" + bugsnagtest.APIProjectID + "/events/" + bugsnagtest.NilPointerEventID
+				session.result(t, "resources/read", mcp.ReadResourceParams{URI: uri}, &result)
+				if len(result.Contents) != 2 || result.Contents[0].MIMEType != "application/json" || result.Contents[1].MIMEType != "text/markdown" {
+					t.Fatalf("resources/read = %+v, want the event as JSON and its stack trace", result.Contents)
+				}
+				if !strings.Contains(result.Contents[1].Text, "**internal/users/store.go:57**") {
+					t.Errorf("stack trace = %s, want the project frames highlighted", result.Contents[1].Text)
+				}
+			})
+
+			t.Run("resources/read of an unknown resource", func(t *testing.T) {
+				resp := session.call(t, "resources/read", mcp.ReadResourceParams{URI: "bugsnag://users/me"})
+				if resp.Error == nil {
+					t.Errorf("resources/read of an unknown resource = %s, want a JSON-RPC error", resp.Result)
+				}
+			})
+
+			t.Run("prompts/list", func(t *testing.T) {
+				var result mcp.ListPromptsResult
+				session.result(t, "prompts/list", nil, &result)
+				var prompts []string
+				for _, prompt := range result.Prompts {
+					prompts = append(prompts, prompt.Name)
+				}
+				want := "investigate_spike release_health triage_error weekly_error_report"
+				if got := strings.Join(prompts, " "); got != want {
+					t.Errorf("prompts/list = %s, want %s", got, want)
+				}
+			})
+
+			t.Run("prompts/get", func(t *testing.T) {
+				resp := session.call(t, "prompts/get", mcp.GetPromptParams{
+					Name:      "triage_error",
+					Arguments: map[string]string{"project": "acme/api", "error": bugsnagtest.NilPointerErrorID},
+				})
+				if resp.Error != nil {
+					t.Fatalf("prompts/get error = %s", resp.Error.Message)
+				}
+				result, err := mcp.ParseGetPromptResult(&resp.Result)
+				if err != nil {
+					t.Fatalf("prompts/get result %s cannot be decoded: %v", resp.Result, err)
+				}
+				if len(result.Messages) != 3 {
+					t.Fatalf("prompts/get = %d messages, want the instructions, the error and its stack trace", len(result.Messages))
+				}
+				if resource, ok := result.Messages[1].Content.(mcp.EmbeddedResource); !ok || resource.Resource.(mcp.TextResourceContents).MIMEType != "application/json" {
+					t.Errorf("prompts/get message = %+v, want the error embedded", result.Messages[1].Content)
+				}
+			})
+
+			t.Run("prompts/get with a missing argument", func(t *testing.T) {
+				resp := session.call(t, "prompts/get", mcp.GetPromptParams{Name: "release_health", Arguments: map[string]string{"project": "acme/api"}})
+				if resp.Error == nil || !strings.Contains(resp.Error.Message, "version") {
+					t.Errorf("prompts/get without version = %s, want a JSON-RPC error", resp.Result)
+				}
+			})
+
+			t.Run("completion/complete", func(t *testing.T) {
+				var result mcp.CompleteResult
+				session.result(t, "completion/complete", map[string]any{
+					"ref":      mcp.ResourceReference{Type: "ref/resource", URI: "bugsnag://projects/{project_id}/events/{id}"},
+					"argument": mcp.CompleteArgument{Name: "id", Value: bugsnagtest.NilPointerEventID[:20]},
+					"context":  mcp.CompleteContext{Arguments: map[string]string{"project_id": "acme/api"}},
+				}, &result)
+				if !slices.Contains(result.Completion.Values, bugsnagtest.NilPointerEventID) {
+					t.Errorf("completion = %+v, want the recent events of the project", result.Completion)
+				}
+			})
+
+			t.Run("unknown method", func(t *testing.T) {
+				resp := session.call(t, "bugsnag/unknown", nil)
+				if resp.Error == nil || resp.Error.Code != mcp.METHOD_NOT_FOUND {
+					t.Errorf("unknown method = %+v, want a method not found error", resp.Error)
+				}
+			})
+		})
+	}
+}
+
+func TestMCPProtocolResourcePages(t *testing.T) {
+	// Enough projects with open errors for their errors to be listed over several pages
+	fixtures := bugsnagtest.DefaultFixtures()
+	for i := range 8 {
+		projectID := fmt.Sprintf("6a0a1b2c3d4e5f6a7b8c9e%02d", i)
+		fixtures.Projects = append(fixtures.Projects, &bugsnagAPI.Project{
+			ID:             projectID,
+			OrganizationID: bugsnagtest.GlobexOrgID,
+			Name:           fmt.Sprintf("Service %d", i),
+			Slug:           fmt.Sprintf("service-%d", i),
+			OpenErrorCount: 1,
+		})
+		fixtures.Errors = append(fixtures.Errors, &bugsnagAPI.Error{
+			ID:         fmt.Sprintf("7e0a1b2c3d4e5f6a7b8c9e%02d", i),
+			ProjectID:  projectID,
+			ErrorClass: "TimeoutError",
+			Message:    "upstream timed out",
+			Status:     "open",
+		})
+	}
+	fake := bugsnagtest.NewServer(fixtures)
+	t.Cleanup(fake.Close)
+	session := connect(t, "stdio", NewMCPServer("bugsnag-mcp", "test", testconfig.New(t, fake)))
+
+	seen := map[string]int{}
+	var cursor mcp.Cursor
+	pages := 0
+	for {
+		var params any
+		if cursor != "" {
+			params = map[string]any{"cursor": cursor}
+		}
+		var result mcp.ListResourcesResult
+		session.result(t, "resources/list", params, &result)
+		for _, resource := range result.Resources {
+			seen[resource.URI]++
+		}
+		pages++
+		if result.NextCursor == "" {
+			break
+		}
+		if pages > 10 {
+			t.Fatalf("resources/list did not end after %d pages", pages)
+		}
+		cursor = result.NextCursor
+	}
+
+	for uri, n := range seen {
+		if n != 1 {
+			t.Errorf("resource %s listed %d times, want once", uri, n)
+		}
+	}
+	// The organizations, the 11 projects and their 12 open errors
+	if want := 1 + 11 + 12; len(seen) != want || pages < 3 {
+		t.Errorf("resources/list listed %d resources over %d pages, want %d over at least 3", len(seen), pages, want)
+	}
+	if seen["bugsnag://projects/6a0a1b2c3d4e5f6a7b8c9e07/errors/7e0a1b2c3d4e5f6a7b8c9e07"] != 1 {
+		t.Errorf("resources/list = %v, want the open error of the last project", seen)
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// rateLimitMetaKey is the key of the Bugsnag API quota in the _meta of tool results.
+const rateLimitMetaKey = "bugsnag/rate_limit"
+
+// rateLimitMiddleware reports the Bugsnag API quota left after a tool call, and how many of its requests
+// had to be retried, in the _meta of its result, so clients can pace themselves during long sessions.
+func rateLimitMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, recorder := bugsnag.WithRateLimitRecorder(ctx)
+		result, err := next(ctx, req)
+		if result == nil {
+			return result, err
+		}
+		if rateLimit := recorder.RateLimit(); rateLimit != nil {
+			if result.Meta == nil {
+				result.Meta = &mcp.Meta{}
+			}
+			if result.Meta.AdditionalFields == nil {
+				result.Meta.AdditionalFields = map[string]any{}
+			}
+			result.Meta.AdditionalFields[rateLimitMetaKey] = rateLimit
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "7")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := bugsnagAPI.NewClient("token",
+		bugsnagAPI.WithBaseURL(server.URL),
+		bugsnagAPI.WithHTTPClient(&http.Client{Transport: bugsnag.RetryTransport(http.DefaultTransport, bugsnag.RetryOptions{})}),
+	)
+
+	tests := []struct {
+		name     string
+		handler  func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		wantMeta bool
+	}{
+		{
+			name: "calling the API",
+			handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				var v map[string]any
+				if _, err := bugsnag.List(ctx, client, "projects/abc", nil, &v); err != nil {
+					return nil, err
+				}
+				return mcp.NewToolResultText("ok"), nil
+			},
+			wantMeta: true,
+		},
+		{
+			name: "not calling the API",
+			handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ok"), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := rateLimitMiddleware(tt.handler)(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("tool call error = %v", err)
+			}
+			var rateLimit *bugsnag.RateLimit
+			ok := false
+			if result.Meta != nil {
+				rateLimit, ok = result.Meta.AdditionalFields[rateLimitMetaKey].(*bugsnag.RateLimit)
+			}
+			if ok != tt.wantMeta {
+				t.Fatalf("_meta = %v, want the rate limit: %t", result.Meta, tt.wantMeta)
+			}
+			if ok && (rateLimit.Limit != 10 || rateLimit.Remaining != 7) {
+				t.Errorf("rate limit = %+v, want limit 10 and 7 remaining", rateLimit)
+			}
+		})
+	}
+}
@@ -2,72 +2,241 @@ package server
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/sazap10/bugsnag-mcp/pkg/auth"
 	"github.com/sazap10/bugsnag-mcp/pkg/config"
+	"github.com/sazap10/bugsnag-mcp/pkg/prompts"
 	"github.com/sazap10/bugsnag-mcp/pkg/resources"
 	"github.com/sazap10/bugsnag-mcp/pkg/tools"
 )
 
+// BugsnagTokenHeader is the header clients of the network transports can send their own Bugsnag auth token in,
+// so the Bugsnag requests made on their behalf are made as them.
+const BugsnagTokenHeader = "X-Bugsnag-Token"
+
+// Server is an MCP server that keeps track of its in-flight tool calls, so they can be drained on shutdown.
+type Server struct {
+	*mcpserver.MCPServer
+
+	cfg   *config.Config
+	calls *callTracker
+}
+
 // NewMCPServer creates a new MCP server with the given name, version, and configuration.
-func NewMCPServer(name, version string, cfg *config.Config, hooks ...*mcpserver.Hooks) *mcpserver.MCPServer {
+func NewMCPServer(name, version string, cfg *config.Config, hooks ...*mcpserver.Hooks) *Server {
+	calls := newCallTracker()
 	opts := []mcpserver.ServerOption{
 		mcpserver.WithResourceCapabilities(true, true),
 		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithPromptCapabilities(false),
+		mcpserver.WithCompletions(),
+		mcpserver.WithResourceCompletionProvider(&completionProvider{cfg: cfg}),
+		mcpserver.WithPromptCompletionProvider(&completionProvider{cfg: cfg}),
 		mcpserver.WithLogging(),
+		mcpserver.WithToolHandlerMiddleware(calls.middleware),
+		mcpserver.WithToolHandlerMiddleware(rateLimitMiddleware),
+		mcpserver.WithToolHandlerMiddleware(tools.BudgetMiddleware(cfg.MaxOutputChars)),
 	}
 
-	// Add hooks if provided
-	for _, hook := range hooks {
-		opts = append(opts, mcpserver.WithHooks(hook))
+	// Add hooks if provided. As only the last hooks take effect, a copy of them is used, so the
+	// hook listing the dynamic resources can be added without changing the caller's.
+	serverHooks := &mcpserver.Hooks{}
+	if n := len(hooks); n > 0 {
+		*serverHooks = *hooks[n-1]
+		serverHooks.OnAfterListResources = slices.Clone(serverHooks.OnAfterListResources)
 	}
+	opts = append(opts, mcpserver.WithHooks(serverHooks))
 
 	// Create the MCP server
 	server := mcpserver.NewMCPServer(name, version, opts...)
 
 	// Register the resources
-	registerResources(server, cfg)
+	registerResources(server, serverHooks, cfg)
 
 	// Register the tools
 	registerTools(server, cfg)
 
-	return server
+	// Register the prompts
+	registerPrompts(server, cfg)
+
+	return &Server{MCPServer: server, cfg: cfg, calls: calls}
 }
 
 // registerResources registers the resources with the MCP server.
-func registerResources(server *mcpserver.MCPServer, cfg *config.Config) {
+// A resource is only registered when the tool exposing the same data is enabled, so hiding a tool does not
+// leave its data readable. The projects of the user and their open errors are listed with the resources
+// through hooks, as concrete resources of the templates.
+func registerResources(server *mcpserver.MCPServer, hooks *mcpserver.Hooks, cfg *config.Config) {
+	enabled := func(toolID string) bool {
+		return cfg.ToolEnabled(toolID, tools.Mutates(toolID))
+	}
+
 	// Add the organization resource
 	orgResource := resources.NewOrganizationResource()
-	server.AddResource(orgResource, resources.HandleOrganizationResource(cfg))
+	if enabled(tools.GetUserOrganizationsToolID) {
+		server.AddResource(orgResource, resources.HandleOrganizationResource(cfg))
+	}
 	// Add the project resource template
 	projectResource := resources.NewProjectResource()
-	server.AddResourceTemplate(projectResource, resources.HandleProjectResource(cfg))
+	if enabled(tools.GetUserProjectsToolID) {
+		server.AddResourceTemplate(projectResource, resources.HandleProjectResource(cfg))
+	}
 	// Add the event resource template
 	eventResource := resources.NewEventResource()
-	server.AddResourceTemplate(eventResource, resources.HandleEventResource(cfg))
+	if enabled(tools.GetProjectEventToolID) {
+		server.AddResourceTemplate(eventResource, resources.HandleEventResource(cfg))
+	}
+	// Add the error resource template
+	errorResource := resources.NewErrorResource()
+	if enabled(tools.GetProjectErrorToolID) {
+		server.AddResourceTemplate(errorResource, resources.HandleErrorResource(cfg))
+	}
+	// List the projects and their open errors
+	if enabled(tools.GetUserProjectsToolID) {
+		hooks.AddAfterListResources(listProjectResources(cfg, enabled(tools.GetProjectErrorToolID)))
+	}
+}
+
+// listProjectResources returns the hook adding the projects of the user to the first page of resources. With
+// includeErrors, their open errors are listed on the following pages, a few projects at a time, so a page never
+// makes more than a handful of API calls. The hook owns those pages and their cursors: it replaces whatever the
+// server listed for them, so they do not depend on how the server paginates. When the projects cannot be
+// listed, the other resources are still returned.
+func listProjectResources(cfg *config.Config, includeErrors bool) mcpserver.OnAfterListResourcesFunc {
+	return func(ctx context.Context, id any, req *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		switch cursor := req.Params.Cursor; {
+		case cursor == "":
+			listed, next, err := resources.ListProjectResources(ctx, cfg, includeErrors)
+			if err != nil {
+				slog.Warn("Failed to list the project resources", slog.Any("error", err))
+				return
+			}
+			result.Resources = append(result.Resources, listed...)
+			if result.NextCursor == "" {
+				result.NextCursor = next
+			}
+		case includeErrors && resources.IsErrorsCursor(cursor):
+			listed, next, err := resources.ListErrorResources(ctx, cfg, cursor)
+			if err != nil {
+				slog.Warn("Failed to list the open error resources", slog.Any("error", err))
+				next = ""
+			}
+			if listed == nil {
+				listed = []mcp.Resource{}
+			}
+			result.Resources = listed
+			result.NextCursor = next
+		}
+	}
 }
 
 // registerTools registers the tools with the MCP server.
+// Only the tools enabled by the configuration are registered, so the others are never advertised to clients.
 func registerTools(server *mcpserver.MCPServer, cfg *config.Config) {
+	var toolIDs []string
+	addTool := func(tool mcp.Tool, handler mcpserver.ToolHandlerFunc) {
+		toolIDs = append(toolIDs, tool.Name)
+		if !cfg.ToolEnabled(tool.Name, tools.Mutates(tool.Name)) {
+			slog.Debug("Tool disabled", slog.String("tool", tool.Name))
+			return
+		}
+		server.AddTool(tool, handler)
+	}
+
 	orgTool := tools.NewGetUserOrganizationsTool()
-	server.AddTool(orgTool, tools.HandleGetUserOrganizationsTool(cfg))
+	addTool(orgTool, tools.HandleGetUserOrganizationsTool(cfg))
 
 	projectTool := tools.NewGetUserProjectsTool()
-	server.AddTool(projectTool, tools.HandleGetUserProjectsTool(cfg))
+	addTool(projectTool, tools.HandleGetUserProjectsTool(cfg))
 
 	eventTool := tools.NewGetProjectEventTool()
-	server.AddTool(eventTool, tools.HandleGetProjectEventTool(cfg))
+	addTool(eventTool, tools.HandleGetProjectEventTool(cfg))
 
 	eventsTool := tools.NewGetProjectEventsTool()
-	server.AddTool(eventsTool, tools.HandleGetProjectEventsTool(cfg))
+	addTool(eventsTool, tools.HandleGetProjectEventsTool(cfg))
+
+	errorsTool := tools.NewListProjectErrorsTool()
+	addTool(errorsTool, tools.HandleListProjectErrorsTool(cfg))
+
+	errorTool := tools.NewGetProjectErrorTool()
+	addTool(errorTool, tools.HandleGetProjectErrorTool(cfg))
+
+	updateErrorTool := tools.NewUpdateErrorStatusTool()
+	addTool(updateErrorTool, tools.HandleUpdateErrorStatusTool(cfg))
+
+	bulkUpdateTool := tools.NewBulkUpdateErrorsTool()
+	addTool(bulkUpdateTool, tools.HandleBulkUpdateErrorsTool(cfg))
+
+	pivotsTool := tools.NewGetErrorPivotsTool()
+	addTool(pivotsTool, tools.HandleGetErrorPivotsTool(cfg))
+
+	errorTrendTool := tools.NewGetErrorTrendTool()
+	addTool(errorTrendTool, tools.HandleGetErrorTrendTool(cfg))
+
+	projectTrendTool := tools.NewGetProjectTrendTool()
+	addTool(projectTrendTool, tools.HandleGetProjectTrendTool(cfg))
+
+	listReleasesTool := tools.NewListReleasesTool()
+	addTool(listReleasesTool, tools.HandleListReleasesTool(cfg))
+
+	getReleaseTool := tools.NewGetReleaseTool()
+	addTool(getReleaseTool, tools.HandleGetReleaseTool(cfg))
+
+	releaseStabilityTool := tools.NewGetReleaseStabilityTool()
+	addTool(releaseStabilityTool, tools.HandleGetReleaseStabilityTool(cfg))
+
+	openLinkTool := tools.NewOpenBugsnagLinkTool()
+	addTool(openLinkTool, tools.HandleOpenBugsnagLinkTool(cfg))
+
+	for _, id := range cfg.UnknownTools(toolIDs) {
+		slog.Warn("Unknown tool ID in the allowed or denied tools", slog.String("tool", id))
+	}
+}
+
+// registerPrompts registers the prompts with the MCP server.
+// Like resources, a prompt is only registered when the tools exposing the data it pre-loads are enabled.
+func registerPrompts(server *mcpserver.MCPServer, cfg *config.Config) {
+	enabled := func(toolIDs ...string) bool {
+		for _, id := range toolIDs {
+			if !cfg.ToolEnabled(id, tools.Mutates(id)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if enabled(tools.GetProjectErrorToolID) {
+		server.AddPrompt(prompts.NewTriageErrorPrompt(), prompts.HandleTriageErrorPrompt(cfg))
+	}
+	if enabled(tools.ListProjectErrorsToolID) {
+		server.AddPrompt(prompts.NewInvestigateSpikePrompt(), prompts.HandleInvestigateSpikePrompt(cfg))
+		server.AddPrompt(prompts.NewReleaseHealthPrompt(), prompts.HandleReleaseHealthPrompt(cfg))
+	}
+	if enabled(tools.GetUserProjectsToolID, tools.ListProjectErrorsToolID) {
+		server.AddPrompt(prompts.NewWeeklyErrorReportPrompt(), prompts.HandleWeeklyErrorReportPrompt(cfg))
+	}
 }
 
 // ServeStdio starts the MCP server with stdio transport.
-func ServeStdio(ctx context.Context, server *mcpserver.MCPServer) error {
+// It returns nil once ctx is cancelled or the client closes stdin.
+func ServeStdio(ctx context.Context, server *Server) error {
+	return serveStdio(ctx, server, os.Stdin, os.Stdout)
+}
+
+// serveStdio serves the MCP server over in and out, standing for stdin and stdout.
+func serveStdio(ctx context.Context, server *Server, in io.Reader, out io.Writer) error {
 	// Create a new stdio transport
-	stdioTransport := mcpserver.NewStdioServer(server)
+	stdioTransport := mcpserver.NewStdioServer(server.MCPServer)
 
 	// create context function
 	// This function can be used to set up the context for the server
@@ -79,14 +248,112 @@ func ServeStdio(ctx context.Context, server *mcpserver.MCPServer) error {
 	stdioTransport.SetContextFunc(contextFunc)
 
 	// Start the server with the stdio transport
-	return stdioTransport.Listen(ctx, os.Stdin, os.Stdout)
+	if err := stdioTransport.Listen(ctx, in, out); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// SSEOptions configures the SSE transport.
+type SSEOptions struct {
+	// DrainTimeout is how long in-flight tool calls are given to finish on shutdown.
+	DrainTimeout time.Duration
+	// Auth authenticates the clients. Nil accepts every client.
+	Auth *auth.Authenticator
 }
 
 // ServeSSE starts the MCP server with SSE transport.
-func ServeSSE(ctx context.Context, server *mcpserver.MCPServer, addr string) error {
-	sseServer := mcpserver.NewSSEServer(server)
+// When ctx is cancelled, the in-flight tool calls are given up to opts.DrainTimeout to finish before the
+// sessions are closed. It returns ErrDrainTimeout if they had to be cancelled.
+func ServeSSE(ctx context.Context, server *Server, addr string, opts SSEOptions) error {
+	srv := &http.Server{Addr: addr}
+	sseServer := mcpserver.NewSSEServer(server.MCPServer,
+		mcpserver.WithHTTPServer(srv),
+		mcpserver.WithSSEContextFunc(server.bugsnagSession),
+	)
+	srv.Handler = opts.Auth.Middleware(server.requireBugsnagToken(sseServer))
 
 	//start the server with the SSE transport
 	slog.Info("Starting SSE server", slog.String("address", addr))
-	return sseServer.Start(addr)
+	return serveUntilDone(ctx, server.calls, opts.DrainTimeout,
+		func() error { return sseServer.Start(addr) },
+		sseServer.Shutdown,
+	)
+}
+
+// HTTPOptions configures the streamable HTTP transport.
+type HTTPOptions struct {
+	// EndpointPath is the path the MCP endpoint is served on.
+	EndpointPath string
+	// Stateless disables session tracking, so every request is handled independently.
+	Stateless bool
+	// HeartbeatInterval is the interval of the pings sent on open streams. Zero disables them.
+	HeartbeatInterval time.Duration
+	// DrainTimeout is how long in-flight tool calls are given to finish on shutdown.
+	DrainTimeout time.Duration
+	// Auth authenticates the clients. Nil accepts every client.
+	Auth *auth.Authenticator
+}
+
+// ServeHTTP starts the MCP server with streamable HTTP transport.
+// When ctx is cancelled, the in-flight tool calls are given up to opts.DrainTimeout to finish before the
+// open streams are closed. It returns ErrDrainTimeout if they had to be cancelled.
+func ServeHTTP(ctx context.Context, server *Server, addr string, opts HTTPOptions) error {
+	// Streams stay open until their request context is cancelled, so the connections get a base
+	// context that is cancelled once the tool calls have been drained.
+	connCtx, closeConns := context.WithCancel(context.Background())
+	defer closeConns()
+	mux := http.NewServeMux()
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return connCtx },
+	}
+
+	httpOpts := []mcpserver.StreamableHTTPOption{
+		mcpserver.WithEndpointPath(opts.EndpointPath),
+		mcpserver.WithStateLess(opts.Stateless),
+		mcpserver.WithStreamableHTTPServer(srv),
+		mcpserver.WithHTTPContextFunc(server.bugsnagSession),
+	}
+	if opts.HeartbeatInterval > 0 {
+		httpOpts = append(httpOpts, mcpserver.WithHeartbeatInterval(opts.HeartbeatInterval))
+	}
+	httpServer := mcpserver.NewStreamableHTTPServer(server.MCPServer, httpOpts...)
+	mux.Handle(opts.EndpointPath, opts.Auth.Middleware(server.requireBugsnagToken(httpServer)))
+
+	// start the server with the streamable HTTP transport
+	slog.Info("Starting streamable HTTP server", slog.String("address", addr), slog.String("endpoint", opts.EndpointPath))
+	return serveUntilDone(ctx, server.calls, opts.DrainTimeout,
+		func() error { return httpServer.Start(addr) },
+		func(ctx context.Context) error {
+			closeConns()
+			return httpServer.Shutdown(ctx)
+		},
+	)
+}
+
+// bugsnagSession makes the Bugsnag requests of a tool call or resource read use the auth token sent in the
+// BugsnagTokenHeader of the request, if any, instead of the configured one.
+func (s *Server) bugsnagSession(ctx context.Context, r *http.Request) context.Context {
+	token := strings.TrimSpace(r.Header.Get(BugsnagTokenHeader))
+	if token == "" {
+		return ctx
+	}
+	return s.cfg.WithBugsnagToken(ctx, token)
+}
+
+// requireBugsnagToken rejects requests without a BugsnagTokenHeader with 401 Unauthorized when no Bugsnag
+// auth token is configured to fall back to.
+func (s *Server) requireBugsnagToken(next http.Handler) http.Handler {
+	if s.cfg == nil || s.cfg.AuthToken != "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimSpace(r.Header.Get(BugsnagTokenHeader)) == "" {
+			http.Error(w, "missing "+BugsnagTokenHeader+" header", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
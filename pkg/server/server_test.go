@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+	"github.com/sazap10/bugsnag-mcp/pkg/prompts"
+	"github.com/sazap10/bugsnag-mcp/pkg/tools"
+)
+
+func TestBugsnagSession(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	server := &Server{cfg: cfg}
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if got := cfg.Client(server.bugsnagSession(context.Background(), r)); got != cfg.APIClient {
+		t.Error("request without a token should use the configured client")
+	}
+
+	r.Header.Set(BugsnagTokenHeader, "session-token")
+	if got := cfg.Client(server.bugsnagSession(context.Background(), r)); got == cfg.APIClient {
+		t.Error("request with a token should use a client of its own")
+	}
+}
+
+func TestRequireBugsnagToken(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultToken string
+		header       string
+		wantStatus   int
+	}{
+		{name: "configured token", defaultToken: "default", wantStatus: http.StatusOK},
+		{name: "session token", header: "session-token", wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{cfg: &config.Config{AuthToken: tt.defaultToken}}
+			handler := server.requireBugsnagToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				r.Header.Set(BugsnagTokenHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRegisterTools(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		wantTool   string
+		wantHidden string
+	}{
+		{name: "read-only", cfg: config.Config{ReadOnly: true}, wantTool: tools.GetProjectErrorToolID, wantHidden: tools.UpdateErrorStatusToolID},
+		{name: "allowed", cfg: config.Config{AllowedTools: []string{tools.ListReleasesToolID}}, wantTool: tools.ListReleasesToolID, wantHidden: tools.GetReleaseToolID},
+		{name: "denied", cfg: config.Config{DeniedTools: []string{tools.BulkUpdateErrorsToolID}}, wantTool: tools.UpdateErrorStatusToolID, wantHidden: tools.BulkUpdateErrorsToolID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test", "0.0.1", &tt.cfg)
+			resp := server.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+			result, ok := resp.(mcp.JSONRPCResponse)
+			if !ok {
+				t.Fatalf("tools/list response = %#v, want a result", resp)
+			}
+			var advertised []string
+			for _, tool := range result.Result.(mcp.ListToolsResult).Tools {
+				advertised = append(advertised, tool.Name)
+			}
+			if !slices.Contains(advertised, tt.wantTool) {
+				t.Errorf("advertised tools = %v, want %s", advertised, tt.wantTool)
+			}
+			if slices.Contains(advertised, tt.wantHidden) {
+				t.Errorf("advertised tools = %v, want %s hidden", advertised, tt.wantHidden)
+			}
+		})
+	}
+}
+
+func TestRegisterPrompts(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		wantPrompt string
+		wantHidden string
+	}{
+		{name: "read-only", cfg: config.Config{ReadOnly: true}, wantPrompt: prompts.TriageErrorPromptID},
+		{name: "allowed", cfg: config.Config{AllowedTools: []string{tools.ListProjectErrorsToolID}}, wantPrompt: prompts.ReleaseHealthPromptID, wantHidden: prompts.WeeklyErrorReportPromptID},
+		{name: "denied", cfg: config.Config{DeniedTools: []string{tools.GetProjectErrorToolID}}, wantPrompt: prompts.InvestigateSpikePromptID, wantHidden: prompts.TriageErrorPromptID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test", "0.0.1", &tt.cfg)
+			resp := server.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`))
+			result, ok := resp.(mcp.JSONRPCResponse)
+			if !ok {
+				t.Fatalf("prompts/list response = %#v, want a result", resp)
+			}
+			var advertised []string
+			for _, prompt := range result.Result.(mcp.ListPromptsResult).Prompts {
+				advertised = append(advertised, prompt.Name)
+			}
+			if !slices.Contains(advertised, tt.wantPrompt) {
+				t.Errorf("advertised prompts = %v, want %s", advertised, tt.wantPrompt)
+			}
+			if tt.wantHidden != "" && slices.Contains(advertised, tt.wantHidden) {
+				t.Errorf("advertised prompts = %v, want %s hidden", advertised, tt.wantHidden)
+			}
+		})
+	}
+}
+
+func TestListResources(t *testing.T) {
+	fake := bugsnagtest.NewServer(bugsnagtest.DefaultFixtures())
+	t.Cleanup(fake.Close)
+
+	tests := []struct {
+		name       string
+		denied     []string
+		fail       bool
+		wantCounts []int
+	}{
+		{name: "projects, then their open errors", wantCounts: []int{4, 4}},
+		{name: "errors hidden", denied: []string{tools.GetProjectErrorToolID}, wantCounts: []int{4}},
+		{name: "projects hidden", denied: []string{tools.GetUserProjectsToolID}, wantCounts: []int{1}},
+		{name: "listing failure", fail: true, wantCounts: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testconfig.New(t, fake)
+			cfg.DeniedTools = tt.denied
+			if tt.fail {
+				fake.Fail(bugsnagtest.Fault{Path: "/user/organizations", Status: http.StatusInternalServerError, Times: 1})
+			}
+
+			// The caller's hooks still run, and are left as they are
+			calls := 0
+			hooks := &mcpserver.Hooks{}
+			hooks.AddAfterListResources(func(context.Context, any, *mcp.ListResourcesRequest, *mcp.ListResourcesResult) { calls++ })
+			server := NewMCPServer("test", "0.0.1", cfg, hooks)
+
+			var counts []int
+			var cursor mcp.Cursor
+			for page := 0; page == 0 || cursor != ""; page++ {
+				if page == 10 {
+					t.Fatal("resources/list did not reach the last page")
+				}
+				req, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": page, "method": "resources/list", "params": map[string]any{"cursor": cursor}})
+				resp := server.HandleMessage(context.Background(), req)
+				result, ok := resp.(mcp.JSONRPCResponse)
+				if !ok {
+					t.Fatalf("resources/list response = %#v, want a result", resp)
+				}
+				listed := result.Result.(mcp.ListResourcesResult)
+				hasErrors := slices.ContainsFunc(listed.Resources, func(r mcp.Resource) bool { return strings.Contains(r.URI, "/errors/") })
+				if hasErrors != (page > 0) {
+					t.Errorf("page %d has errors %t, want them only after the first page", page, hasErrors)
+				}
+				counts = append(counts, len(listed.Resources))
+				cursor = listed.NextCursor
+			}
+			if !slices.Equal(counts, tt.wantCounts) {
+				t.Errorf("resources/list pages have %v resources, want %v", counts, tt.wantCounts)
+			}
+			if calls != len(counts) || len(hooks.OnAfterListResources) != 1 {
+				t.Errorf("caller's hook called %d times, %d hooks, want called once a page and unchanged", calls, len(hooks.OnAfterListResources))
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// ErrDrainTimeout is returned when the in-flight tool calls did not finish within the drain timeout and were cancelled.
+var ErrDrainTimeout = errors.New("drain timeout exceeded, in-flight tool calls were cancelled")
+
+// shutdownGracePeriod bounds how long cancelled tool calls and closing the connections may take once draining is over.
+const shutdownGracePeriod = 5 * time.Second
+
+// callTracker keeps track of the in-flight tool calls, so they can be drained and cancelled on shutdown.
+type callTracker struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+
+	// ctx is cancelled to cancel the in-flight tool calls.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newCallTracker() *callTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &callTracker{ctx: ctx, cancel: cancel}
+}
+
+// middleware tracks each tool call and cancels its context when the tracker cancels the in-flight calls.
+// Tool calls made once draining has started are rejected.
+func (t *callTracker) middleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			return mcp.NewToolResultError("the server is shutting down, retry the call once it is back"), nil
+		}
+		t.wg.Add(1)
+		t.mu.Unlock()
+		defer t.wg.Done()
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(t.ctx, cancel)
+		defer stop()
+
+		return next(ctx, req)
+	}
+}
+
+// drain stops accepting tool calls and waits for the in-flight ones to finish. If ctx is done first, the
+// remaining calls are cancelled and ErrDrainTimeout is returned.
+func (t *callTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.cancel()
+	select {
+	case <-done:
+	case <-time.After(shutdownGracePeriod):
+		slog.Warn("Tool calls did not return after being cancelled")
+	}
+	return ErrDrainTimeout
+}
+
+// serveUntilDone runs start until it fails or ctx is cancelled. On cancellation the in-flight tool calls
+// are given up to drainTimeout to finish, then shutdown closes the listener and the remaining connections.
+func serveUntilDone(ctx context.Context, calls *callTracker, drainTimeout time.Duration, start func() error, shutdown func(context.Context) error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- start()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Draining in-flight tool calls", slog.Duration("timeout", drainTimeout))
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+	drainErr := calls.drain(drainCtx)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancelShutdown()
+	if err := shutdown(shutdownCtx); err != nil {
+		return errors.Join(drainErr, fmt.Errorf("failed to shut down: %w", err))
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Join(drainErr, err)
+	}
+	return drainErr
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// waitingHandler is a tool handler that returns after delay, or with an error when its context is cancelled first.
+func waitingHandler(delay time.Duration, started chan<- struct{}) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if started != nil {
+			close(started)
+		}
+		select {
+		case <-time.After(delay):
+			return mcp.NewToolResultText("done"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestCallTrackerDrain(t *testing.T) {
+	t.Run("waits for in-flight calls", func(t *testing.T) {
+		calls := newCallTracker()
+		started := make(chan struct{})
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := calls.middleware(waitingHandler(50*time.Millisecond, started))(context.Background(), mcp.CallToolRequest{})
+			errCh <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := calls.drain(ctx); err != nil {
+			t.Fatalf("drain() error = %v, want nil", err)
+		}
+		if err := <-errCh; err != nil {
+			t.Errorf("tool call error = %v, want it to finish", err)
+		}
+	})
+
+	t.Run("cancels calls after the timeout", func(t *testing.T) {
+		calls := newCallTracker()
+		started := make(chan struct{})
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := calls.middleware(waitingHandler(time.Minute, started))(context.Background(), mcp.CallToolRequest{})
+			errCh <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := calls.drain(ctx); !errors.Is(err, ErrDrainTimeout) {
+			t.Fatalf("drain() error = %v, want ErrDrainTimeout", err)
+		}
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Errorf("tool call error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("rejects calls once draining", func(t *testing.T) {
+		calls := newCallTracker()
+		if err := calls.drain(context.Background()); err != nil {
+			t.Fatalf("drain() error = %v", err)
+		}
+		result, err := calls.middleware(waitingHandler(0, nil))(context.Background(), mcp.CallToolRequest{})
+		if err != nil || result == nil || !result.IsError {
+			t.Errorf("tool call = %+v, %v, want an error result", result, err)
+		}
+	})
+}
+
+func TestServeHTTPGracefulShutdown(t *testing.T) {
+	calls := newCallTracker()
+	mcpServer := mcpserver.NewMCPServer("test", "0.0.1", mcpserver.WithToolHandlerMiddleware(calls.middleware))
+	started := make(chan struct{})
+	mcpServer.AddTool(mcp.NewTool("slow"), waitingHandler(100*time.Millisecond, started))
+	server := &Server{MCPServer: mcpServer, calls: calls}
+
+	// Reserve a free port for the server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- ServeHTTP(ctx, server, addr, HTTPOptions{EndpointPath: "/mcp", Stateless: true, DrainTimeout: time.Second})
+	}()
+
+	// Call the slow tool, and shut down while it is in flight
+	body := make(chan string, 1)
+	go func() {
+		var (
+			resp *http.Response
+			err  error
+		)
+		for range 50 {
+			resp, err = http.Post("http://"+addr+"/mcp", "application/json",
+				strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`))
+			if err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	<-started
+	cancel()
+
+	if got := <-body; !strings.Contains(got, `"text":"done"`) {
+		t.Errorf("tool call response = %s, want the in-flight call to finish", got)
+	}
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			t.Errorf("ServeHTTP() error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeHTTP() did not return after the context was cancelled")
+	}
+}
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// trimPass drops one kind of low-value field from a JSON result.
+type trimPass struct {
+	// name describes the dropped fields in the note added to the result.
+	name string
+	// trim drops the fields from the decoded result and returns how many values it dropped.
+	trim func(v any) int
+	// hint says how to fetch the dropped fields from the result of the named tool.
+	hint func(tool string) string
+}
+
+// trimPasses are applied in order until a JSON result fits its budget, from the least to the most valuable fields.
+var trimPasses = []trimPass{
+	{name: "breadcrumbs", trim: dropKey("breadcrumbs"), hint: eventDetailsHint},
+	{name: "metadata", trim: dropKey("metaData"), hint: eventDetailsHint},
+	{name: "non-project stack frames", trim: dropLibraryFrames, hint: eventDetailsHint},
+	{name: "threads", trim: dropKey("threads"), hint: eventDetailsHint},
+}
+
+// eventDetailsHint says how to see the complete details of the events in the result of a tool. The result of
+// get_project_event is a single event already, so it points to the event resource, which is not trimmed.
+func eventDetailsHint(tool string) string {
+	if tool == GetProjectEventToolID {
+		return "read the bugsnag://projects/{project_id}/events/{id} resource, which is not trimmed, to see the complete event"
+	}
+	return "retrieve a single event with " + GetProjectEventToolID + " to see its complete details"
+}
+
+// BudgetMiddleware caps the size of tool results at maxChars characters (about 4 per token), counted as
+// Unicode code points rather than bytes. JSON results are
+// trimmed field by field, dropping breadcrumbs, metadata, non-project stack frames and threads, then the last items
+// of lists; other results are cut at a line boundary. A note says what was dropped and how to fetch the rest.
+// Zero disables the budget.
+func BudgetMiddleware(maxChars int) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, req)
+			if err != nil || result == nil || result.IsError || maxChars <= 0 || len(result.Content) != 1 {
+				return result, err
+			}
+			content, ok := result.Content[0].(mcp.TextContent)
+			if !ok || utf8.RuneCountInString(content.Text) <= maxChars {
+				return result, err
+			}
+
+			text, note := fitBudget(req.Params.Name, req.GetString("cursor", ""), content.Text, maxChars)
+			content.Text = text
+			result.Content = []mcp.Content{content, mcp.NewTextContent(note)}
+			return result, err
+		}
+	}
+}
+
+// fitBudget trims the result text of a tool to maxChars, returning the trimmed text and a note describing what
+// was dropped. cursor is the cursor the result was fetched with, if any.
+func fitBudget(tool, cursor, text string, maxChars int) (string, string) {
+	var v any
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return truncateText(tool, text, maxChars)
+	}
+
+	var dropped, hints []string
+	addHint := func(hint string) {
+		if !slices.Contains(hints, hint) {
+			hints = append(hints, hint)
+		}
+	}
+	encoded := text
+	for _, pass := range trimPasses {
+		n := pass.trim(v)
+		if n == 0 {
+			continue
+		}
+		dropped = append(dropped, fmt.Sprintf("%d %s", n, pass.name))
+		addHint(pass.hint(tool))
+		if encoded = marshalResult(v); utf8.RuneCountInString(encoded) <= maxChars {
+			return encoded, budgetNote(maxChars, dropped, hints)
+		}
+	}
+
+	// Keep as many of the first items of a list result as fit
+	if list, ok := v.(map[string]any); ok {
+		if items, ok := list["items"].([]any); ok && len(items) > 1 {
+			total := len(items)
+			delete(list, "next_cursor")
+			kept := sort.Search(total, func(n int) bool {
+				list["items"] = items[:n+1]
+				return utf8.RuneCountInString(marshalResult(list)) > maxChars
+			})
+			kept = max(kept, 1)
+			list["items"] = items[:kept]
+			encoded = marshalResult(list)
+			dropped = append(dropped, fmt.Sprintf("%d of %d items", total-kept, total))
+			if cursor != "" {
+				// Without the cursor the call would start again from the first page
+				addHint(fmt.Sprintf("repeat this call with the same cursor and per_page=%d to page through the rest of the items (next_cursor was removed, as it would skip the dropped items)", kept))
+			} else {
+				addHint(fmt.Sprintf("call again with per_page=%d to page through all the items (next_cursor was removed, as it would skip the dropped items)", kept))
+			}
+			if utf8.RuneCountInString(encoded) <= maxChars {
+				return encoded, budgetNote(maxChars, dropped, hints)
+			}
+		}
+	}
+
+	text, note := truncateText(tool, encoded, maxChars)
+	if len(dropped) > 0 {
+		note = budgetNote(maxChars, dropped, hints) + " " + note
+	}
+	return text, note
+}
+
+// truncateText cuts the result text of a tool at the last line boundary within maxChars characters.
+func truncateText(tool, text string, maxChars int) (string, string) {
+	cut, n := text, 0
+	// Count characters rather than bytes, so multi-byte characters are neither over-counted nor cut in half
+	for i := range text {
+		if n == maxChars {
+			cut = text[:i]
+			break
+		}
+		n++
+	}
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	}
+	note := fmt.Sprintf("The output was cut at %d of %d characters to fit the output budget. "+
+		"Narrow the request, e.g. with filters, a smaller per_page or the summary format, to see the rest.",
+		utf8.RuneCountInString(cut), utf8.RuneCountInString(text))
+	if tool == GetProjectEventsToolID {
+		// The cursor is listed above the events, so it survives the cut, but it continues after the whole page
+		note += " The next cursor continues after the whole page, skipping the events cut here."
+	}
+	return cut, note
+}
+
+// budgetNote describes the fields dropped to fit the budget, and how to fetch them.
+func budgetNote(maxChars int, dropped, hints []string) string {
+	return fmt.Sprintf("The output was trimmed to fit the %d character output budget: dropped %s. To see the rest, %s.",
+		maxChars, strings.Join(dropped, ", "), strings.Join(hints, "; "))
+}
+
+// marshalResult encodes a trimmed result as the handlers do, indented and without escaping HTML.
+func marshalResult(v any) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// dropKey returns a trim function deleting key from every object, counting the elements of its values.
+func dropKey(key string) func(v any) int {
+	return func(v any) int {
+		n := 0
+		walkObjects(v, func(obj map[string]any) {
+			value, ok := obj[key]
+			if !ok {
+				return
+			}
+			switch value := value.(type) {
+			case []any:
+				n += len(value)
+			case map[string]any:
+				n += len(value)
+			default:
+				n++
+			}
+			delete(obj, key)
+		})
+		return n
+	}
+}
+
+// dropLibraryFrames deletes the frames that are not in the project from every stack trace, counting them.
+func dropLibraryFrames(v any) int {
+	n := 0
+	walkObjects(v, func(obj map[string]any) {
+		frames, ok := obj["stacktrace"].([]any)
+		if !ok {
+			return
+		}
+		kept := frames[:0]
+		for _, frame := range frames {
+			if f, ok := frame.(map[string]any); ok && f["in_project"] == true {
+				kept = append(kept, frame)
+			}
+		}
+		n += len(frames) - len(kept)
+		obj["stacktrace"] = kept
+	})
+	return n
+}
+
+// walkObjects calls fn for every object in v, parents before their children.
+func walkObjects(v any, fn func(map[string]any)) {
+	switch v := v.(type) {
+	case map[string]any:
+		fn(v)
+		for _, child := range v {
+			walkObjects(child, fn)
+		}
+	case []any:
+		for _, child := range v {
+			walkObjects(child, fn)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// callWithBudget calls a tool returning result through the budget middleware.
+func callWithBudget(t *testing.T, maxChars int, result *mcp.CallToolResult) *mcp.CallToolResult {
+	t.Helper()
+	return callToolWithBudget(t, "", maxChars, result)
+}
+
+// callToolWithBudget calls the named tool returning result through the budget middleware.
+func callToolWithBudget(t *testing.T, tool string, maxChars int, result *mcp.CallToolResult) *mcp.CallToolResult {
+	t.Helper()
+	handler := BudgetMiddleware(maxChars)(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return result, nil
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool
+	got, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("tool call error = %v", err)
+	}
+	return got
+}
+
+// resultTexts returns the text contents of a result.
+func resultTexts(result *mcp.CallToolResult) []string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return texts
+}
+
+// jsonResult returns a tool result of v encoded as the handlers do.
+func jsonResult(t *testing.T, v any) *mcp.CallToolResult {
+	t.Helper()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent() error = %v", err)
+	}
+	return mcp.NewToolResultText(string(data))
+}
+
+func TestBudgetMiddleware(t *testing.T) {
+	var breadcrumbs []map[string]any
+	for i := range 50 {
+		breadcrumbs = append(breadcrumbs, map[string]any{"name": fmt.Sprintf("crumb %d", i), "type": "navigation"})
+	}
+	event := map[string]any{
+		"id":          "evt1",
+		"breadcrumbs": breadcrumbs,
+		"metaData":    map[string]any{"request": map[string]any{"url": "/users"}},
+		"exceptions": []map[string]any{{
+			"errorClass": "RuntimeError",
+			"stacktrace": []map[string]any{
+				{"method": "handle", "file": "app/users.go", "in_project": true},
+				{"method": "serve", "file": "net/http/server.go"},
+			},
+		}},
+	}
+
+	t.Run("leaves results within the budget alone", func(t *testing.T) {
+		result := jsonResult(t, event)
+		texts := resultTexts(callWithBudget(t, 100000, result))
+		if len(texts) != 1 {
+			t.Errorf("result has %d contents, want 1", len(texts))
+		}
+	})
+
+	t.Run("drops breadcrumbs first", func(t *testing.T) {
+		texts := resultTexts(callWithBudget(t, 1000, jsonResult(t, event)))
+		if len(texts) != 2 {
+			t.Fatalf("result has %d contents, want the trimmed result and a note", len(texts))
+		}
+		if len(texts[0]) > 1000 {
+			t.Errorf("trimmed result has %d characters, want at most 1000", len(texts[0]))
+		}
+		var got map[string]any
+		if err := json.Unmarshal([]byte(texts[0]), &got); err != nil {
+			t.Fatalf("trimmed result is not valid JSON: %v", err)
+		}
+		if _, ok := got["breadcrumbs"]; ok {
+			t.Error("trimmed result kept the breadcrumbs")
+		}
+		if _, ok := got["metaData"]; !ok {
+			t.Error("trimmed result dropped the metadata, want only the breadcrumbs dropped")
+		}
+		if !strings.Contains(texts[1], "dropped 50 breadcrumbs.") || !strings.Contains(texts[1], "get_project_event") {
+			t.Errorf("note = %q, want the dropped breadcrumbs and how to see them", texts[1])
+		}
+	})
+
+	t.Run("drops non-project frames after metadata", func(t *testing.T) {
+		texts := resultTexts(callWithBudget(t, 200, jsonResult(t, event)))
+		if strings.Contains(texts[0], "net/http/server.go") || !strings.Contains(texts[0], "app/users.go") {
+			t.Errorf("trimmed result = %s, want only the project frames", texts[0])
+		}
+		if want := "dropped 50 breadcrumbs, 1 metadata, 1 non-project stack frames."; !strings.Contains(texts[1], want) {
+			t.Errorf("note = %q, want %q", texts[1], want)
+		}
+	})
+
+	t.Run("keeps the first items of lists", func(t *testing.T) {
+		var items []map[string]any
+		for i := range 100 {
+			items = append(items, map[string]any{"id": fmt.Sprintf("error-%03d", i)})
+		}
+		result := jsonResult(t, map[string]any{"items": items, "next_cursor": "abc", "total_count": 500})
+		texts := resultTexts(callWithBudget(t, 2000, result))
+
+		var got struct {
+			Items      []map[string]any `json:"items"`
+			NextCursor string           `json:"next_cursor"`
+			TotalCount int              `json:"total_count"`
+		}
+		if err := json.Unmarshal([]byte(texts[0]), &got); err != nil {
+			t.Fatalf("trimmed result is not valid JSON: %v", err)
+		}
+		if len(texts[0]) > 2000 || len(got.Items) < 10 || got.Items[0]["id"] != "error-000" {
+			t.Errorf("trimmed result has %d characters and %d items, want the most first items that fit", len(texts[0]), len(got.Items))
+		}
+		if got.NextCursor != "" || got.TotalCount != 500 {
+			t.Errorf("trimmed result cursor = %q, total = %d, want the cursor removed and the total kept", got.NextCursor, got.TotalCount)
+		}
+		if want := fmt.Sprintf("per_page=%d", len(got.Items)); !strings.Contains(texts[1], want) {
+			t.Errorf("note = %q, want %q", texts[1], want)
+		}
+	})
+
+	t.Run("keeps the cursor of later pages of lists", func(t *testing.T) {
+		var items []map[string]any
+		for i := range 100 {
+			items = append(items, map[string]any{"id": fmt.Sprintf("error-%03d", i)})
+		}
+		result := jsonResult(t, map[string]any{"items": items, "next_cursor": "page-3", "total_count": 500})
+		handler := BudgetMiddleware(2000)(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return result, nil
+		})
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"cursor": "page-2"}
+		got, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("tool call error = %v", err)
+		}
+
+		texts := resultTexts(got)
+		if len(texts) != 2 || !strings.Contains(texts[1], "same cursor and per_page=") {
+			t.Errorf("note = %q, want it to say to repeat the cursor with a smaller per_page", texts)
+		}
+	})
+
+	t.Run("cuts text at a line boundary", func(t *testing.T) {
+		text := strings.Repeat("- a line of a summary\n", 100)
+		texts := resultTexts(callWithBudget(t, 100, mcp.NewToolResultText(text)))
+		if len(texts[0]) > 100 || strings.HasSuffix(texts[0], "summ") || !strings.HasSuffix(texts[0], "summary") {
+			t.Errorf("cut text = %q, want whole lines", texts[0])
+		}
+		if !strings.Contains(texts[1], "was cut at") {
+			t.Errorf("note = %q, want it to say the output was cut", texts[1])
+		}
+	})
+
+	t.Run("counts characters rather than bytes", func(t *testing.T) {
+		// 50 characters but 100 bytes per line
+		line := strings.Repeat("é", 49) + "\n"
+		if texts := resultTexts(callWithBudget(t, 100, mcp.NewToolResultText(line+line))); len(texts) != 1 {
+			t.Errorf("result has %d contents, want a result within the budget untouched", len(texts))
+		}
+
+		texts := resultTexts(callWithBudget(t, 120, mcp.NewToolResultText(strings.Repeat(line, 3))))
+		if texts[0] != line+strings.TrimSuffix(line, "\n") {
+			t.Errorf("cut text = %q, want the first two lines", texts[0])
+		}
+		if !strings.Contains(texts[1], "cut at 99 of 150 characters") {
+			t.Errorf("note = %q, want the cut counted in characters", texts[1])
+		}
+	})
+
+	t.Run("points get_project_event to the event resource", func(t *testing.T) {
+		texts := resultTexts(callToolWithBudget(t, GetProjectEventToolID, 1000, jsonResult(t, event)))
+		if strings.Contains(texts[1], "with get_project_event") || !strings.Contains(texts[1], "bugsnag://projects/{project_id}/events/{id}") {
+			t.Errorf("note = %q, want the event resource rather than the tool itself", texts[1])
+		}
+	})
+
+	t.Run("keeps the cursor of a cut summary list", func(t *testing.T) {
+		events := make([]*bugsnagAPI.Event, 50)
+		for i := range events {
+			events[i] = testEvent()
+		}
+		text := formatEventList(events, formatSummary, "next-page", 500)
+		texts := resultTexts(callToolWithBudget(t, GetProjectEventsToolID, 1000, mcp.NewToolResultText(text)))
+		if len(texts[0]) > 1000 || !strings.Contains(texts[0], "Next cursor: `next-page`") || !strings.Contains(texts[0], "Showing 50 of 500 events.") {
+			t.Errorf("cut text = %q, want the total and cursor kept", texts[0])
+		}
+		if !strings.Contains(texts[1], "skipping the events cut here") {
+			t.Errorf("note = %q, want it to say the cursor skips the cut events", texts[1])
+		}
+	})
+
+	t.Run("leaves errors alone", func(t *testing.T) {
+		result := mcp.NewToolResultError(strings.Repeat("x", 500))
+		if texts := resultTexts(callWithBudget(t, 100, result)); len(texts[0]) != 500 {
+			t.Errorf("error result has %d characters, want it untouched", len(texts[0]))
+		}
+	})
+
+	t.Run("zero disables the budget", func(t *testing.T) {
+		if texts := resultTexts(callWithBudget(t, 0, jsonResult(t, event))); len(texts) != 1 {
+			t.Errorf("result has %d contents, want it untouched", len(texts))
+		}
+	})
+}
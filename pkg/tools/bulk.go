@@ -0,0 +1,367 @@
+package tools
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+const (
+	// maxBulkErrors is the maximum number of errors a single bulk update can touch.
+	maxBulkErrors = 100
+	// confirmationTokenTTL is how long the confirmation token of a dry run can apply the update.
+	confirmationTokenTTL = 5 * time.Minute
+)
+
+// confirmationSecret keys the confirmation tokens. It is random per process, so a token cannot be
+// computed without a dry run and does not outlive the server.
+var confirmationSecret = func() []byte {
+	secret := make([]byte, sha256.Size)
+	rand.Read(secret)
+	return secret
+}()
+
+// bulkTarget describes an error that a bulk update would touch.
+type bulkTarget struct {
+	ID          string `json:"id"`
+	ErrorClass  string `json:"error_class,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Events      int    `json:"events,omitempty"`
+	Users       int    `json:"users,omitempty"`
+	LookupError string `json:"lookup_error,omitempty"`
+}
+
+// bulkDryRun is the result of a bulk update dry run.
+type bulkDryRun struct {
+	DryRun            bool         `json:"dry_run"`
+	Status            string       `json:"status"`
+	Filter            string       `json:"filter,omitempty"`
+	Errors            []bulkTarget `json:"errors"`
+	ConfirmationToken string       `json:"confirmation_token,omitempty"`
+	Message           string       `json:"message"`
+}
+
+// bulkUpdateResult is the outcome of a bulk update for a single error.
+type bulkUpdateResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Status  string `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// bulkApply is the result of an applied bulk update.
+type bulkApply struct {
+	DryRun    bool               `json:"dry_run"`
+	Status    string             `json:"status"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []bulkUpdateResult `json:"results"`
+}
+
+// NewBulkUpdateErrorsTool returns the MCP tool for changing the status of many errors at once.
+func NewBulkUpdateErrorsTool() mcp.Tool {
+	return mcp.NewTool(
+		BulkUpdateErrorsToolID,
+		mcp.WithDescription("Updates the status of multiple errors in a project. "+
+			"Called without 'confirmation_token' it performs a dry run listing exactly which errors would be touched and returns a confirmation token; "+
+			"call it again with the same arguments and that token within 5 minutes to apply the change"),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project the errors belong to"),
+		),
+		mcp.WithArray(
+			"error_ids",
+			mcp.Description("The IDs/urls of the errors to update. Either 'error_ids' or 'filter' must be set"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString(
+			"filter",
+			mcp.Description(`A filter expression selecting the errors to update, e.g. 'error.status=open event.class=NoMethodError app.release_stage!=development'. Values containing spaces can be double quoted`),
+		),
+		mcp.WithString(
+			"status",
+			mcp.Required(),
+			mcp.Description("The new status of the errors"),
+			mcp.Enum("fixed", "ignored", "snoozed", "open"),
+		),
+		mcp.WithNumber(
+			"snooze_seconds",
+			mcp.Description("Snooze the errors until this many seconds have passed (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_occurrences",
+			mcp.Description("Snooze the errors until they occur this many times within 'snooze_hours' hours (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_hours",
+			mcp.Description("The window in hours used with 'snooze_occurrences' (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_additional_occurrences",
+			mcp.Description("Snooze the errors until they occur this many more times (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_additional_users",
+			mcp.Description("Snooze the errors until this many more users are affected (status 'snoozed' only)"),
+		),
+		mcp.WithString(
+			"confirmation_token",
+			mcp.Description("The confirmation token returned by the dry run. When set, the update is applied"),
+		),
+	)
+}
+
+// HandleBulkUpdateErrorsTool handles the tool call to dry run or apply a status change to multiple errors.
+func HandleBulkUpdateErrorsTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+		status, err := req.RequireString("status")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'status': %v", err)), nil
+		}
+		errorIDs := req.GetStringSlice("error_ids", nil)
+		filterExpr := req.GetString("filter", "")
+		if (len(errorIDs) == 0) == (filterExpr == "") {
+			return mcp.NewToolResultError("exactly one of 'error_ids' or 'filter' must be set"), nil
+		}
+
+		update, err := buildStatusUpdate(status, snoozeOptions{
+			Seconds:               req.GetInt("snooze_seconds", 0),
+			Occurrences:           req.GetInt("snooze_occurrences", 0),
+			Hours:                 req.GetInt("snooze_hours", 0),
+			AdditionalOccurrences: req.GetInt("snooze_additional_occurrences", 0),
+			AdditionalUsers:       req.GetInt("snooze_additional_users", 0),
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid status update: %v", err)), nil
+		}
+
+		confirmation := req.GetString("confirmation_token", "")
+
+		// Resolve the set of errors the update would touch
+		var ids []string
+		var targets []bulkTarget
+		var filters []bugsnagAPI.Filter
+		if filterExpr != "" {
+			filters, err = bugsnag.ParseFilterExpression(filterExpr)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid filter: %v", err)), nil
+			}
+			targets, err = listBulkTargets(ctx, cfg, projectID, filters)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve errors: %v", err)), nil
+			}
+			for _, target := range targets {
+				ids = append(ids, target.ID)
+			}
+		} else {
+			ids, err = normalizeErrorIDs(errorIDs)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid error ID or link: %v", err)), nil
+			}
+		}
+		if len(ids) > maxBulkErrors {
+			return mcp.NewToolResultError(fmt.Sprintf("the update would touch more than the maximum of %d errors; narrow the selection", maxBulkErrors)), nil
+		}
+
+		var result any
+		if confirmation == "" {
+			token, err := confirmationToken(projectID, update, ids, timeNow())
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to compute confirmation token: %v", err)), nil
+			}
+			if filterExpr == "" {
+				targets = describeBulkTargets(ctx, cfg, projectID, ids)
+			}
+			dryRun := bulkDryRun{
+				DryRun:            true,
+				Status:            status,
+				Filter:            bugsnag.FormatFilters(filters),
+				Errors:            targets,
+				ConfirmationToken: token,
+				Message:           fmt.Sprintf("%d error(s) would be updated. Call the tool again with the same arguments and this confirmation_token within %s to apply the change.", len(ids), confirmationTokenTTL),
+			}
+			if len(ids) == 0 {
+				dryRun.ConfirmationToken = ""
+				dryRun.Message = "No errors match, nothing would be updated."
+			}
+			result = dryRun
+		} else {
+			if err := checkConfirmationToken(confirmation, projectID, update, ids, timeNow()); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%v; run a new dry run", err)), nil
+			}
+			result = applyBulkUpdate(ctx, cfg, projectID, status, ids, update)
+		}
+
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+}
+
+// listBulkTargets lists the errors of a project matching the filters.
+// One more error than the maximum is retrieved so oversized selections can be detected.
+func listBulkTargets(ctx context.Context, cfg *config.Config, projectID string, filters []bugsnagAPI.Filter) ([]bulkTarget, error) {
+	errs, _, err := bugsnag.ListPage[*bugsnagAPI.Error](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/errors", bugsnag.EncodeFilters(filters), bugsnag.PageOptions{
+		MaxItems: maxBulkErrors + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]bulkTarget, 0, len(errs))
+	for _, e := range errs {
+		targets = append(targets, newBulkTarget(e))
+	}
+	return targets, nil
+}
+
+// normalizeErrorIDs converts error IDs or links into a de-duplicated list of error IDs.
+func normalizeErrorIDs(idsOrLinks []string) ([]string, error) {
+	seen := make(map[string]bool, len(idsOrLinks))
+	ids := make([]string, 0, len(idsOrLinks))
+	for _, idOrLink := range idsOrLinks {
+		id, err := bugsnag.ErrorIDFromIDOrLink(idOrLink)
+		if err != nil {
+			return nil, err
+		}
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// describeBulkTargets retrieves the details of each error so a dry run can show what would be touched.
+// Errors that cannot be retrieved are still listed, with the lookup failure.
+func describeBulkTargets(ctx context.Context, cfg *config.Config, projectID string, ids []string) []bulkTarget {
+	targets := make([]bulkTarget, 0, len(ids))
+	for _, id := range ids {
+		e, _, err := cfg.Client(ctx).Errors.GetError(ctx, projectID, id)
+		if err != nil {
+			targets = append(targets, bulkTarget{ID: id, LookupError: err.Error()})
+			continue
+		}
+		targets = append(targets, newBulkTarget(e))
+	}
+	return targets
+}
+
+// applyBulkUpdate applies the update to each error, recording the outcome per error.
+func applyBulkUpdate(ctx context.Context, cfg *config.Config, projectID, status string, ids []string, update *bugsnagAPI.ErrorUpdateRequest) bulkApply {
+	result := bulkApply{
+		Status:  status,
+		Results: make([]bulkUpdateResult, 0, len(ids)),
+	}
+	for _, id := range ids {
+		updated, _, err := cfg.Client(ctx).Errors.UpdateError(ctx, projectID, id, update)
+		if err != nil {
+			result.Failed++
+			result.Results = append(result.Results, bulkUpdateResult{ID: id, Error: err.Error()})
+			continue
+		}
+		result.Succeeded++
+		result.Results = append(result.Results, bulkUpdateResult{ID: id, Success: true, Status: updated.Status})
+	}
+	return result
+}
+
+// newBulkTarget summarises an error for a bulk update dry run.
+func newBulkTarget(e *bugsnagAPI.Error) bulkTarget {
+	return bulkTarget{
+		ID:         e.ID,
+		ErrorClass: e.ErrorClass,
+		Message:    e.Message,
+		Status:     e.Status,
+		Events:     e.Events,
+		Users:      e.Users,
+	}
+}
+
+// confirmationToken derives a token binding a dry run to the exact project, change and set of errors.
+// It is an HMAC of those and the time it was issued, so no server side state is needed between the
+// dry run and the apply call, and it expires after confirmationTokenTTL.
+func confirmationToken(projectID string, update *bugsnagAPI.ErrorUpdateRequest, ids []string, issued time.Time) (string, error) {
+	issuedAt := strconv.FormatInt(issued.Unix(), 10)
+	mac, err := confirmationMAC(issuedAt, projectID, update, ids)
+	if err != nil {
+		return "", err
+	}
+	return issuedAt + "." + hex.EncodeToString(mac), nil
+}
+
+// checkConfirmationToken checks that token was issued by confirmationToken for the project, change
+// and set of errors, and has not expired at now.
+func checkConfirmationToken(token, projectID string, update *bugsnagAPI.ErrorUpdateRequest, ids []string, now time.Time) error {
+	issuedAt, encodedMAC, ok := strings.Cut(token, ".")
+	issued, err := strconv.ParseInt(issuedAt, 10, 64)
+	got, hexErr := hex.DecodeString(encodedMAC)
+	if !ok || err != nil || hexErr != nil {
+		return errors.New("the confirmation token is malformed")
+	}
+
+	want, err := confirmationMAC(issuedAt, projectID, update, ids)
+	if err != nil {
+		return fmt.Errorf("failed to compute confirmation token: %w", err)
+	}
+	if !hmac.Equal(got, want) {
+		return errors.New("the confirmation token does not match the errors and change requested, the selection may have changed since the dry run")
+	}
+	if age := now.Sub(time.Unix(issued, 0)); age > confirmationTokenTTL || age < -time.Minute {
+		return fmt.Errorf("the confirmation token has expired, it is valid for %s", confirmationTokenTTL)
+	}
+	return nil
+}
+
+// confirmationMAC computes the HMAC of a confirmation token, independently of the order of the IDs.
+func confirmationMAC(issuedAt, projectID string, update *bugsnagAPI.ErrorUpdateRequest, ids []string) ([]byte, error) {
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	h := hmac.New(sha256.New, confirmationSecret)
+	h.Write([]byte(issuedAt))
+	h.Write([]byte{0})
+	h.Write([]byte(projectID))
+	h.Write([]byte{0})
+	h.Write(updateJSON)
+	for _, id := range sorted {
+		h.Write([]byte{0})
+		h.Write([]byte(id))
+	}
+	return h.Sum(nil), nil
+}
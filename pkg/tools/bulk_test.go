@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestNormalizeErrorIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		want      []string
+		wantError bool
+	}{
+		{
+			name:  "plain ids",
+			input: []string{"a1", "b2"},
+			want:  []string{"a1", "b2"},
+		},
+		{
+			name:  "links and ids are de-duplicated",
+			input: []string{"a1", "https://app.bugsnag.com/org/proj/errors/a1", "https://app.bugsnag.com/org/proj/errors/c3?event_id=x"},
+			want:  []string{"a1", "c3"},
+		},
+		{
+			name:  "empty ids are skipped",
+			input: []string{"", "a1"},
+			want:  []string{"a1"},
+		},
+		{
+			name:      "link without error id",
+			input:     []string{"https://app.bugsnag.com/org/proj"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeErrorIDs(tt.input)
+			if (err != nil) != tt.wantError {
+				t.Errorf("normalizeErrorIDs() error = %v, wantError %v", err, tt.wantError)
+				return
+			}
+			if !tt.wantError && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeErrorIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmationToken(t *testing.T) {
+	fix := &bugsnagAPI.ErrorUpdateRequest{Operation: "fix"}
+	ignore := &bugsnagAPI.ErrorUpdateRequest{Operation: "ignore"}
+	issued := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	token := func(projectID string, update *bugsnagAPI.ErrorUpdateRequest, ids []string) string {
+		t.Helper()
+		got, err := confirmationToken(projectID, update, ids, issued)
+		if err != nil {
+			t.Fatalf("confirmationToken() error = %v", err)
+		}
+		return got
+	}
+
+	base := token("proj", fix, []string{"a", "b", "c"})
+	if got := token("proj", fix, []string{"c", "a", "b"}); got != base {
+		t.Errorf("confirmationToken() should not depend on ID order, got %v want %v", got, base)
+	}
+	if got := token("other", fix, []string{"a", "b", "c"}); got == base {
+		t.Errorf("confirmationToken() should change with the project")
+	}
+	if got := token("proj", ignore, []string{"a", "b", "c"}); got == base {
+		t.Errorf("confirmationToken() should change with the update")
+	}
+	if got := token("proj", fix, []string{"a", "b"}); got == base {
+		t.Errorf("confirmationToken() should change with the set of errors")
+	}
+	if got := token("proj", fix, []string{"ab", "c"}); got == token("proj", fix, []string{"a", "bc"}) {
+		t.Errorf("confirmationToken() should not collide when IDs are split differently")
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		ids     []string
+		now     time.Time
+		wantErr string
+	}{
+		{name: "valid", token: base, ids: []string{"b", "c", "a"}, now: issued.Add(time.Minute)},
+		{name: "other errors", token: base, ids: []string{"a", "b"}, now: issued, wantErr: "does not match"},
+		{name: "expired", token: base, ids: []string{"a", "b", "c"}, now: issued.Add(confirmationTokenTTL + time.Second), wantErr: "expired"},
+		{name: "issued time changed", token: "1" + base, ids: []string{"a", "b", "c"}, now: issued, wantErr: "does not match"},
+		{name: "unkeyed hash", token: strings.Split(base, ".")[0] + "." + strings.Repeat("0", 64), ids: []string{"a", "b", "c"}, now: issued, wantErr: "does not match"},
+		{name: "malformed", token: "not-a-token", ids: []string{"a", "b", "c"}, now: issued, wantErr: "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConfirmationToken(tt.token, "proj", fix, tt.ids, tt.now)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkConfirmationToken() error = %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkConfirmationToken() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
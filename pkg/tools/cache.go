@@ -0,0 +1,25 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// withCacheBypass returns the tool option for the argument used to skip the cached Bugsnag responses.
+func withCacheBypass() mcp.ToolOption {
+	return mcp.WithBoolean(
+		"cache_bypass",
+		mcp.Description("Retrieve fresh data from Bugsnag instead of recently cached responses, e.g. right after a change made outside this server"),
+	)
+}
+
+// cacheContext returns the context for the Bugsnag requests of a tool call, skipping the cache if the call asks for it.
+func cacheContext(ctx context.Context, req mcp.CallToolRequest) context.Context {
+	if req.GetBool("cache_bypass", false) {
+		return bugsnag.WithCacheBypass(ctx)
+	}
+	return ctx
+}
@@ -0,0 +1,322 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+// NewListProjectErrorsTool returns the MCP tool for listing the errors of a project.
+func NewListProjectErrorsTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Retrieves the errors (grouped events) for a project from Bugsnag"),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve errors for"),
+		),
+		mcp.WithString(
+			"sort",
+			mcp.Description("The field to sort errors by"),
+			mcp.Enum("last_seen", "first_seen", "users", "events", "unsorted"),
+		),
+		mcp.WithString(
+			"direction",
+			mcp.Description("The sort direction"),
+			mcp.Enum("asc", "desc"),
+		),
+	}
+	opts = append(opts, withPagination()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(ListProjectErrorsToolID, opts...)
+}
+
+// HandleListProjectErrorsTool handles the tool call to retrieve all errors for a given project.
+func HandleListProjectErrorsTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+
+		query := url.Values{}
+		if sort := req.GetString("sort", ""); sort != "" {
+			query.Set("sort", sort)
+		}
+		if direction := req.GetString("direction", ""); direction != "" {
+			query.Set("direction", direction)
+		}
+
+		errs, page, err := bugsnag.ListPage[*bugsnagAPI.Error](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/errors", query, pageOptions(req))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve errors: %v", err)), nil
+		}
+
+		errsJSON, err := json.MarshalIndent(listResult[*bugsnagAPI.Error]{Items: errs, PageInfo: *page}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal errors: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(errsJSON)), nil
+	}
+}
+
+// NewGetProjectErrorTool returns the MCP tool for retrieving a specific error for a project.
+func NewGetProjectErrorTool() mcp.Tool {
+	return mcp.NewTool(
+		GetProjectErrorToolID,
+		mcp.WithDescription("Retrieves a specific error for a project from Bugsnag, including its class, message, status, severity, first/last seen and occurrence/user counts"),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve the error for"),
+		),
+		mcp.WithString(
+			"error_id",
+			mcp.Required(),
+			mcp.Description("The ID/url of the error to retrieve"),
+		),
+		withCacheBypass(),
+	)
+}
+
+// HandleGetProjectErrorTool handles the tool call to retrieve a specific error for a project by error ID or link.
+func HandleGetProjectErrorTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+		reqParam, err := req.RequireString("error_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'error_id': %v", err)), nil
+		}
+
+		errorID, err := bugsnag.ErrorIDFromIDOrLink(reqParam)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid error ID or link: %v", err)), nil
+		}
+
+		bugsnagErr, _, err := cfg.Client(ctx).Errors.GetError(ctx, projectID, errorID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve error: %v", err)), nil
+		}
+
+		errJSON, err := json.MarshalIndent(bugsnagErr, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal error: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(errJSON)), nil
+	}
+}
+
+// NewUpdateErrorStatusTool returns the MCP tool for changing the workflow status of an error.
+func NewUpdateErrorStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		UpdateErrorStatusToolID,
+		mcp.WithDescription("Updates the status of an error in Bugsnag: mark it fixed, ignored, snoozed or reopen it. Returns the updated error"),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project the error belongs to"),
+		),
+		mcp.WithString(
+			"error_id",
+			mcp.Required(),
+			mcp.Description("The ID/url of the error to update"),
+		),
+		mcp.WithString(
+			"status",
+			mcp.Required(),
+			mcp.Description("The new status of the error"),
+			mcp.Enum("fixed", "ignored", "snoozed", "open"),
+		),
+		mcp.WithNumber(
+			"snooze_seconds",
+			mcp.Description("Snooze the error until this many seconds have passed (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_occurrences",
+			mcp.Description("Snooze the error until it occurs this many times within 'snooze_hours' hours (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_hours",
+			mcp.Description("The window in hours used with 'snooze_occurrences' (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_additional_occurrences",
+			mcp.Description("Snooze the error until it occurs this many more times (status 'snoozed' only)"),
+		),
+		mcp.WithNumber(
+			"snooze_additional_users",
+			mcp.Description("Snooze the error until this many more users are affected (status 'snoozed' only)"),
+		),
+		mcp.WithBoolean(
+			"confirm",
+			mcp.Required(),
+			mcp.Description("Must be set to true to confirm the change should be applied in Bugsnag"),
+		),
+	)
+}
+
+// HandleUpdateErrorStatusTool handles the tool call to change the status of a specific error for a project.
+func HandleUpdateErrorStatusTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+		reqParam, err := req.RequireString("error_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'error_id': %v", err)), nil
+		}
+		status, err := req.RequireString("status")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'status': %v", err)), nil
+		}
+		confirm, err := req.RequireBool("confirm")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'confirm': %v", err)), nil
+		}
+		if !confirm {
+			return mcp.NewToolResultError("the update was not applied: set 'confirm' to true to change the error status"), nil
+		}
+
+		errorID, err := bugsnag.ErrorIDFromIDOrLink(reqParam)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid error ID or link: %v", err)), nil
+		}
+
+		update, err := buildStatusUpdate(status, snoozeOptions{
+			Seconds:               req.GetInt("snooze_seconds", 0),
+			Occurrences:           req.GetInt("snooze_occurrences", 0),
+			Hours:                 req.GetInt("snooze_hours", 0),
+			AdditionalOccurrences: req.GetInt("snooze_additional_occurrences", 0),
+			AdditionalUsers:       req.GetInt("snooze_additional_users", 0),
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid status update: %v", err)), nil
+		}
+
+		updated, _, err := cfg.Client(ctx).Errors.UpdateError(ctx, projectID, errorID, update)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update error: %v", err)), nil
+		}
+
+		errJSON, err := json.MarshalIndent(updated, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal error: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(errJSON)), nil
+	}
+}
+
+// snoozeOptions holds the thresholds after which a snoozed error is reopened.
+// Exactly one rule must be set when snoozing an error.
+type snoozeOptions struct {
+	Seconds               int
+	Occurrences           int
+	Hours                 int
+	AdditionalOccurrences int
+	AdditionalUsers       int
+}
+
+// isZero reports whether no snooze threshold has been set.
+func (o snoozeOptions) isZero() bool {
+	return o == snoozeOptions{}
+}
+
+// reopenRules converts the snooze thresholds into Bugsnag reopen rules.
+func (o snoozeOptions) reopenRules() (*bugsnagAPI.ErrorUpdateReopenRules, error) {
+	var rules []*bugsnagAPI.ErrorUpdateReopenRules
+	if o.Seconds > 0 {
+		rules = append(rules, &bugsnagAPI.ErrorUpdateReopenRules{
+			ReopenIf: "occurs_after",
+			Seconds:  o.Seconds,
+		})
+	}
+	if o.Occurrences > 0 || o.Hours > 0 {
+		if o.Occurrences <= 0 || o.Hours <= 0 {
+			return nil, fmt.Errorf("'snooze_occurrences' and 'snooze_hours' must be set together")
+		}
+		rules = append(rules, &bugsnagAPI.ErrorUpdateReopenRules{
+			ReopenIf:    "n_occurrences_in_m_hours",
+			Occurrences: o.Occurrences,
+			Hours:       o.Hours,
+		})
+	}
+	if o.AdditionalOccurrences > 0 {
+		rules = append(rules, &bugsnagAPI.ErrorUpdateReopenRules{
+			ReopenIf:              "n_additional_occurrences",
+			AdditionalOccurrences: o.AdditionalOccurrences,
+		})
+	}
+	if o.AdditionalUsers > 0 {
+		rules = append(rules, &bugsnagAPI.ErrorUpdateReopenRules{
+			ReopenIf:        "n_additional_users",
+			AdditionalUsers: o.AdditionalUsers,
+		})
+	}
+
+	switch len(rules) {
+	case 0:
+		return nil, fmt.Errorf("snoozing an error requires a time, occurrence or user threshold")
+	case 1:
+		return rules[0], nil
+	default:
+		return nil, fmt.Errorf("only one snooze threshold can be set at a time")
+	}
+}
+
+// buildStatusUpdate builds the Bugsnag update request that moves an error to the given status.
+func buildStatusUpdate(status string, snooze snoozeOptions) (*bugsnagAPI.ErrorUpdateRequest, error) {
+	var operation string
+	switch status {
+	case "fixed":
+		operation = "fix"
+	case "ignored":
+		operation = "ignore"
+	case "open":
+		operation = "open"
+	case "snoozed":
+		rules, err := snooze.reopenRules()
+		if err != nil {
+			return nil, err
+		}
+		return &bugsnagAPI.ErrorUpdateRequest{
+			Operation:   "snooze",
+			ReopenRules: rules,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown status: %s", status)
+	}
+
+	if !snooze.isZero() {
+		return nil, fmt.Errorf("snooze thresholds can only be used with status 'snoozed'")
+	}
+	return &bugsnagAPI.ErrorUpdateRequest{Operation: operation}, nil
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestBuildStatusUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		snooze    snoozeOptions
+		want      *bugsnagAPI.ErrorUpdateRequest
+		wantError bool
+	}{
+		{
+			name:   "fixed",
+			status: "fixed",
+			want:   &bugsnagAPI.ErrorUpdateRequest{Operation: "fix"},
+		},
+		{
+			name:   "ignored",
+			status: "ignored",
+			want:   &bugsnagAPI.ErrorUpdateRequest{Operation: "ignore"},
+		},
+		{
+			name:   "reopen",
+			status: "open",
+			want:   &bugsnagAPI.ErrorUpdateRequest{Operation: "open"},
+		},
+		{
+			name:   "snoozed for a time",
+			status: "snoozed",
+			snooze: snoozeOptions{Seconds: 3600},
+			want: &bugsnagAPI.ErrorUpdateRequest{
+				Operation:   "snooze",
+				ReopenRules: &bugsnagAPI.ErrorUpdateReopenRules{ReopenIf: "occurs_after", Seconds: 3600},
+			},
+		},
+		{
+			name:   "snoozed until occurrences in hours",
+			status: "snoozed",
+			snooze: snoozeOptions{Occurrences: 10, Hours: 24},
+			want: &bugsnagAPI.ErrorUpdateRequest{
+				Operation:   "snooze",
+				ReopenRules: &bugsnagAPI.ErrorUpdateReopenRules{ReopenIf: "n_occurrences_in_m_hours", Occurrences: 10, Hours: 24},
+			},
+		},
+		{
+			name:   "snoozed until additional occurrences",
+			status: "snoozed",
+			snooze: snoozeOptions{AdditionalOccurrences: 100},
+			want: &bugsnagAPI.ErrorUpdateRequest{
+				Operation:   "snooze",
+				ReopenRules: &bugsnagAPI.ErrorUpdateReopenRules{ReopenIf: "n_additional_occurrences", AdditionalOccurrences: 100},
+			},
+		},
+		{
+			name:   "snoozed until additional users",
+			status: "snoozed",
+			snooze: snoozeOptions{AdditionalUsers: 5},
+			want: &bugsnagAPI.ErrorUpdateRequest{
+				Operation:   "snooze",
+				ReopenRules: &bugsnagAPI.ErrorUpdateReopenRules{ReopenIf: "n_additional_users", AdditionalUsers: 5},
+			},
+		},
+		{
+			name:      "snoozed without threshold",
+			status:    "snoozed",
+			wantError: true,
+		},
+		{
+			name:      "snoozed with multiple thresholds",
+			status:    "snoozed",
+			snooze:    snoozeOptions{Seconds: 60, AdditionalUsers: 5},
+			wantError: true,
+		},
+		{
+			name:      "snoozed with occurrences but no hours",
+			status:    "snoozed",
+			snooze:    snoozeOptions{Occurrences: 10},
+			wantError: true,
+		},
+		{
+			name:      "snooze threshold with non-snooze status",
+			status:    "fixed",
+			snooze:    snoozeOptions{Seconds: 60},
+			wantError: true,
+		},
+		{
+			name:      "unknown status",
+			status:    "deleted",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildStatusUpdate(tt.status, tt.snooze)
+			if (err != nil) != tt.wantError {
+				t.Errorf("buildStatusUpdate() error = %v, wantError %v", err, tt.wantError)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildStatusUpdate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// timeNow returns the time relative times in tool arguments are resolved against. Tests replace it.
+var timeNow = time.Now
+
+// eventFilterParams maps the event filter tool arguments to the Bugsnag event fields they filter on.
+var eventFilterParams = []struct {
+	Param       string
+	Field       string
+	Description string
+}{
+	{"release_stage", "app.release_stage", "Only include events from this release stage, e.g. production"},
+	{"app_version", "app.version", "Only include events from this app version"},
+	{"severity", "event.severity", "Only include events with this severity (error, warning or info)"},
+	{"error_class", "event.class", "Only include events with this error class, e.g. NoMethodError"},
+	{"user_id", "user.id", "Only include events affecting this user ID"},
+}
+
+// withEventFilters returns the tool options for the arguments used to filter events.
+func withEventFilters() []mcp.ToolOption {
+	opts := []mcp.ToolOption{
+		mcp.WithString(
+			"since",
+			mcp.Description("Only include events after this time: an RFC 3339 timestamp or a relative time like 30m, 24h, 7d or 2w"),
+		),
+		mcp.WithString(
+			"until",
+			mcp.Description("Only include events before this time: an RFC 3339 timestamp or a relative time like 30m, 24h, 7d or 2w"),
+		),
+	}
+	for _, p := range eventFilterParams {
+		opts = append(opts, mcp.WithString(p.Param, mcp.Description(p.Description)))
+	}
+	opts = append(opts, mcp.WithString(
+		"filter",
+		mcp.Description(`Additional Bugsnag filters as an expression of field=value or field!=value terms, e.g. 'device.osName=Android request.url="/checkout"'`),
+	))
+	return opts
+}
+
+// eventFilters builds the Bugsnag filters from the event filter tool arguments.
+func eventFilters(req mcp.CallToolRequest, now time.Time) ([]bugsnagAPI.Filter, error) {
+	var filters []bugsnagAPI.Filter
+
+	for _, p := range []struct{ Param, Field string }{{"since", "event.since"}, {"until", "event.before"}} {
+		value := req.GetString(p.Param, "")
+		if value == "" {
+			continue
+		}
+		t, err := bugsnag.ParseTime(value, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s': %w", p.Param, err)
+		}
+		filters = append(filters, bugsnagAPI.Filter{
+			Key:   p.Field,
+			Type:  bugsnag.FilterTypeEqual,
+			Value: t.UTC().Format(time.RFC3339),
+		})
+	}
+
+	for _, p := range eventFilterParams {
+		if value := req.GetString(p.Param, ""); value != "" {
+			filters = append(filters, bugsnagAPI.Filter{Key: p.Field, Type: bugsnag.FilterTypeEqual, Value: value})
+		}
+	}
+
+	if expr := req.GetString("filter", ""); expr != "" {
+		extra, err := bugsnag.ParseFilterExpression(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'filter': %w", err)
+		}
+		filters = append(filters, extra...)
+	}
+
+	return filters, nil
+}
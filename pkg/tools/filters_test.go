@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestEventFilters(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []bugsnagAPI.Filter
+		wantErr bool
+	}{
+		{
+			name: "no filters",
+			args: map[string]any{"project_id": "p"},
+			want: nil,
+		},
+		{
+			name: "relative since and absolute until",
+			args: map[string]any{"since": "24h", "until": "2025-06-01T11:00:00+01:00"},
+			want: []bugsnagAPI.Filter{
+				{Key: "event.since", Type: "eq", Value: "2025-05-31T12:00:00Z"},
+				{Key: "event.before", Type: "eq", Value: "2025-06-01T10:00:00Z"},
+			},
+		},
+		{
+			name: "named filters",
+			args: map[string]any{
+				"release_stage": "production",
+				"app_version":   "1.2.3",
+				"severity":      "error",
+				"error_class":   "NoMethodError",
+				"user_id":       "42",
+			},
+			want: []bugsnagAPI.Filter{
+				{Key: "app.release_stage", Type: "eq", Value: "production"},
+				{Key: "app.version", Type: "eq", Value: "1.2.3"},
+				{Key: "event.severity", Type: "eq", Value: "error"},
+				{Key: "event.class", Type: "eq", Value: "NoMethodError"},
+				{Key: "user.id", Type: "eq", Value: "42"},
+			},
+		},
+		{
+			name: "arbitrary filter expression",
+			args: map[string]any{"since": "7d", "filter": "device.osName=Android app.type!=worker"},
+			want: []bugsnagAPI.Filter{
+				{Key: "event.since", Type: "eq", Value: "2025-05-25T12:00:00Z"},
+				{Key: "device.osName", Type: "eq", Value: "Android"},
+				{Key: "app.type", Type: "ne", Value: "worker"},
+			},
+		},
+		{
+			name:    "invalid since",
+			args:    map[string]any{"since": "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter expression",
+			args:    map[string]any{"filter": "device.osName"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			got, err := eventFilters(req, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("eventFilters() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eventFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// Output formats of the event tools
+const (
+	formatSummary  = "summary"
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+const (
+	// summaryFrames is the number of stack frames shown in an event summary.
+	summaryFrames = 10
+	// summaryBreadcrumbs is the number of most recent breadcrumbs shown in an event summary.
+	summaryBreadcrumbs = 10
+	// summaryMetadataKeys is the number of values shown per metadata tab in an event summary.
+	summaryMetadataKeys = 10
+	// summaryValueLength is the length values are truncated to in an event summary.
+	summaryValueLength = 120
+)
+
+// withFormat returns the tool option for the argument choosing how events are rendered, defaulting to defaultFormat.
+func withFormat(defaultFormat string) mcp.ToolOption {
+	return mcp.WithString(
+		"format",
+		mcp.Description(fmt.Sprintf("How to render events: 'summary' for the exception, top of the stack trace with library frames collapsed, key metadata, recent breadcrumbs, "+
+			"user and app info in compact Markdown, or one line per event for lists; 'markdown' for the complete events in Markdown; 'json' for the raw events from the Bugsnag API. "+
+			"Defaults to '%s'", defaultFormat)),
+		mcp.Enum(formatSummary, formatMarkdown, formatJSON),
+	)
+}
+
+// outputFormat returns the format asked for by a tool call, or defaultFormat.
+func outputFormat(req mcp.CallToolRequest, defaultFormat string) (string, error) {
+	switch format := req.GetString("format", defaultFormat); format {
+	case formatSummary, formatMarkdown, formatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format %q, must be one of %s, %s or %s", format, formatSummary, formatMarkdown, formatJSON)
+	}
+}
+
+// formatEvent renders an event as a summary or complete Markdown document.
+func formatEvent(event *bugsnagAPI.Event, format string) string {
+	var b strings.Builder
+	full := format == formatMarkdown
+
+	exception := bugsnagAPI.Exceptions{ErrorClass: "Unknown error"}
+	if len(event.Exceptions) > 0 {
+		exception = event.Exceptions[0]
+	}
+	fmt.Fprintf(&b, "## %s", exception.ErrorClass)
+	if exception.Message != "" {
+		fmt.Fprintf(&b, ": %s", truncate(exception.Message, summaryValueLength, full))
+	}
+	b.WriteString("\n\n")
+
+	handled := "Handled"
+	if event.Unhandled {
+		handled = "Unhandled"
+	}
+	fmt.Fprintf(&b, "%s %s · received %s", handled, orDefault(event.Severity, "error"), event.ReceivedAt.UTC().Format(time.RFC3339))
+	if event.Context != "" {
+		fmt.Fprintf(&b, " · context `%s`", event.Context)
+	}
+	fmt.Fprintf(&b, "\nEvent `%s` of error `%s`", event.ID, event.ErrorID)
+	if event.URL != "" {
+		fmt.Fprintf(&b, " · %s", event.URL)
+	}
+	b.WriteString("\n")
+
+	for i, exception := range event.Exceptions {
+		if i > 0 {
+			if !full {
+				fmt.Fprintf(&b, "\n_%d more chained exception(s), use format 'markdown' to see them_\n", len(event.Exceptions)-1)
+				break
+			}
+			fmt.Fprintf(&b, "\n### Caused by %s: %s\n", exception.ErrorClass, exception.Message)
+		}
+		writeStacktrace(&b, exception.Stacktrace, full)
+	}
+
+	writeFields(&b, "App", [][2]string{
+		{"version", event.App.Version},
+		{"release stage", event.App.ReleaseStage},
+		{"type", event.App.Type},
+		{"bundle version", event.App.BundleVersion},
+		{"duration", formatMillis(event.App.Duration)},
+	})
+	writeFields(&b, "Device", [][2]string{
+		{"os", strings.TrimSpace(event.Device.OsName + " " + event.Device.OsVersion)},
+		{"browser", strings.TrimSpace(event.Device.BrowserName + " " + event.Device.BrowserVersion)},
+		{"model", strings.TrimSpace(event.Device.Manufacturer + " " + event.Device.Model)},
+		{"hostname", event.Device.Hostname},
+	})
+	writeFields(&b, "User", [][2]string{
+		{"id", event.User.ID},
+		{"name", event.User.Name},
+		{"email", event.User.Email},
+	})
+	writeFields(&b, "Request", [][2]string{
+		{"url", strings.TrimSpace(event.Request.HTTPMethod + " " + event.Request.URL)},
+		{"referer", event.Request.Referer},
+		{"client ip", event.Request.ClientIP},
+	})
+
+	if len(event.FeatureFlags) > 0 {
+		b.WriteString("\n### Feature flags\n")
+		for _, flag := range event.FeatureFlags {
+			if flag.VariantName != "" {
+				fmt.Fprintf(&b, "- %s: %s\n", flag.FeatureFlagName, flag.VariantName)
+			} else {
+				fmt.Fprintf(&b, "- %s\n", flag.FeatureFlagName)
+			}
+		}
+	}
+
+	writeMetadata(&b, event.MetaData, full)
+	writeBreadcrumbs(&b, event.Breadcrumbs, full)
+	return b.String()
+}
+
+// writeStacktrace writes the processed stack trace, up to summaryFrames entries in a summary and with the code
+// of every project frame in a complete event.
+func writeStacktrace(b *strings.Builder, frames []bugsnagAPI.Stacktrace, full bool) {
+	if len(frames) == 0 {
+		return
+	}
+	opts := bugsnag.TraceFormatOptions{MaxEntries: summaryFrames}
+	if full {
+		opts = bugsnag.TraceFormatOptions{AllSnippets: true}
+	}
+	b.WriteString("\n### Stack trace\n")
+	b.WriteString(bugsnag.FormatStacktrace(bugsnag.ProcessStacktrace(frames), opts))
+}
+
+// writeFields writes a section of the non-empty fields, or nothing if they are all empty.
+func writeFields(b *strings.Builder, title string, fields [][2]string) {
+	var values []string
+	for _, field := range fields {
+		if field[1] != "" {
+			values = append(values, field[0]+": "+field[1])
+		}
+	}
+	if len(values) > 0 {
+		fmt.Fprintf(b, "\n### %s\n%s\n", title, strings.Join(values, " · "))
+	}
+}
+
+// writeMetadata writes the metadata tabs. Summaries show the first values of each tab, truncated.
+func writeMetadata(b *strings.Builder, metadata map[string]any, full bool) {
+	if len(metadata) == 0 {
+		return
+	}
+	b.WriteString("\n### Metadata\n")
+	for _, tab := range slices.Sorted(maps.Keys(metadata)) {
+		values, ok := metadata[tab].(map[string]any)
+		if !ok {
+			fmt.Fprintf(b, "- **%s**: %s\n", tab, truncate(formatValue(metadata[tab]), summaryValueLength, full))
+			continue
+		}
+		fmt.Fprintf(b, "- **%s**\n", tab)
+		keys := slices.Sorted(maps.Keys(values))
+		for i, key := range keys {
+			if !full && i == summaryMetadataKeys {
+				fmt.Fprintf(b, "  - _%d more value(s)_\n", len(keys)-i)
+				break
+			}
+			fmt.Fprintf(b, "  - %s: %s\n", key, truncate(formatValue(values[key]), summaryValueLength, full))
+		}
+	}
+}
+
+// writeBreadcrumbs writes the most recent breadcrumbs of a summary, or all of them for a complete event.
+func writeBreadcrumbs(b *strings.Builder, breadcrumbs []bugsnagAPI.Breadcrumbs, full bool) {
+	if len(breadcrumbs) == 0 {
+		return
+	}
+	shown := breadcrumbs
+	if !full {
+		shown = breadcrumbs[max(len(breadcrumbs)-summaryBreadcrumbs, 0):]
+		fmt.Fprintf(b, "\n### Last %d of %d breadcrumbs\n", len(shown), len(breadcrumbs))
+	} else {
+		b.WriteString("\n### Breadcrumbs\n")
+	}
+	for _, crumb := range shown {
+		fmt.Fprintf(b, "- %s [%s] %s", crumb.Timestamp.UTC().Format(time.TimeOnly), crumb.Type, crumb.Name)
+		if full && len(crumb.MetaData) > 0 {
+			var values []string
+			for _, key := range slices.Sorted(maps.Keys(crumb.MetaData)) {
+				values = append(values, key+"="+crumb.MetaData[key])
+			}
+			fmt.Fprintf(b, " (%s)", strings.Join(values, ", "))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// formatEventList renders a page of events as one line per event for a summary, or complete Markdown documents.
+func formatEventList(events []*bugsnagAPI.Event, format string, next string, total int) string {
+	var b strings.Builder
+	// The total and cursor come first, so they are kept when the list is cut to fit the output budget
+	if total > 0 {
+		fmt.Fprintf(&b, "Showing %d of %d events.\n", len(events), total)
+	}
+	if next != "" {
+		fmt.Fprintf(&b, "Next cursor: `%s`\n", next)
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	if format == formatMarkdown {
+		for i, event := range events {
+			if i > 0 {
+				b.WriteString("\n---\n\n")
+			}
+			b.WriteString(formatEvent(event, formatMarkdown))
+		}
+	} else {
+		for _, event := range events {
+			class, message := "Unknown error", ""
+			if len(event.Exceptions) > 0 {
+				class, message = event.Exceptions[0].ErrorClass, event.Exceptions[0].Message
+			}
+			fmt.Fprintf(&b, "- `%s` %s %s: %s", event.ID, event.ReceivedAt.UTC().Format(time.RFC3339), class, truncate(message, summaryValueLength, false))
+			if event.Context != "" {
+				fmt.Fprintf(&b, " · `%s`", event.Context)
+			}
+			if event.App.Version != "" {
+				fmt.Fprintf(&b, " · v%s", event.App.Version)
+			}
+			if event.User.ID != "" {
+				fmt.Fprintf(&b, " · user %s", event.User.ID)
+			}
+			fmt.Fprintf(&b, " (error `%s`)\n", event.ErrorID)
+		}
+	}
+	if len(events) == 0 {
+		b.WriteString("No events found.\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatValue renders a metadata value, as JSON unless it is a string.
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// truncate shortens s to n characters unless full is set.
+func truncate(s string, n int, full bool) string {
+	if full || len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+// formatMillis renders a duration in milliseconds, or "" for zero.
+func formatMillis(ms int) string {
+	if ms == 0 {
+		return ""
+	}
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
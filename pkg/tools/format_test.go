@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+// testEvent returns an event with a chained exception, library and project frames, 15 breadcrumbs and 12 request metadata values.
+func testEvent() *bugsnagAPI.Event {
+	receivedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	frames := []bugsnagAPI.Stacktrace{
+		{Method: "net/http.(*conn).serve", File: "net/http/server.go", LineNumber: 2092},
+		{Method: "main.handleUsers", File: "cmd/api/users.go", LineNumber: 42, InProject: true, Code: map[string]string{"41": "user := load(id)", "42": "return user.Name"}},
+		{Method: "main.load", File: "cmd/api/store.go", LineNumber: 7, InProject: true},
+	}
+	for i := range 12 {
+		frames = append(frames, bugsnagAPI.Stacktrace{Method: fmt.Sprintf("main.helper%d", i), File: "cmd/api/helpers.go", LineNumber: i + 1, InProject: true})
+	}
+
+	var breadcrumbs []bugsnagAPI.Breadcrumbs
+	for i := range 15 {
+		breadcrumbs = append(breadcrumbs, bugsnagAPI.Breadcrumbs{
+			Name:      fmt.Sprintf("crumb-%02d", i),
+			Type:      "navigation",
+			Timestamp: receivedAt.Add(time.Duration(i-15) * time.Second),
+			MetaData:  map[string]string{"to": fmt.Sprintf("/page/%d", i)},
+		})
+	}
+
+	request := map[string]any{}
+	for i := range 12 {
+		request[fmt.Sprintf("header-%02d", i)] = "value"
+	}
+	request["header-00"] = strings.Repeat("x", 200)
+
+	return &bugsnagAPI.Event{
+		ID:         "evt1",
+		ErrorID:    "err1",
+		ReceivedAt: receivedAt,
+		Context:    "GET /users",
+		Severity:   "error",
+		Unhandled:  true,
+		Exceptions: []bugsnagAPI.Exceptions{
+			{ErrorClass: "*errors.errorString", Message: "nil pointer dereference", Stacktrace: frames},
+			{ErrorClass: "sql.ErrNoRows", Message: "no rows in result set", Stacktrace: frames[:1]},
+		},
+		App:         bugsnagAPI.App{Version: "1.2.3", ReleaseStage: "production"},
+		User:        bugsnagAPI.User{ID: "u1", Email: "jane@example.com"},
+		Breadcrumbs: breadcrumbs,
+		MetaData:    map[string]any{"request": request, "team": "payments"},
+	}
+}
+
+func TestFormatEventSummary(t *testing.T) {
+	got := formatEvent(testEvent(), formatSummary)
+
+	for _, want := range []string{
+		"## *errors.errorString: nil pointer dereference",
+		"Unhandled error · received 2025-06-01T12:00:00Z · context `GET /users`",
+		"### Stack trace\n- net/http/server.go:2092 `net/http.(*conn).serve`\n- **cmd/api/users.go:42** `main.handleUsers`\n",
+		"  >   42 | return user.Name",
+		"- _5 more entries_",
+		"_1 more chained exception(s)",
+		"version: 1.2.3 · release stage: production",
+		"id: u1 · email: jane@example.com",
+		"- **team**: payments",
+		"  - _2 more value(s)_",
+		"### Last 10 of 15 breadcrumbs",
+		"[navigation] crumb-14",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatEvent() summary does not contain %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"main.helper8", "crumb-04", "sql.ErrNoRows", strings.Repeat("x", 200)} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("formatEvent() summary contains %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestFormatEventMarkdown(t *testing.T) {
+	got := formatEvent(testEvent(), formatMarkdown)
+
+	for _, want := range []string{
+		"### Stack trace\n- net/http/server.go:2092 `net/http.(*conn).serve`",
+		"      41 | user := load(id)\n  >   42 | return user.Name",
+		"- **cmd/api/helpers.go:12** `main.helper11`",
+		"### Caused by sql.ErrNoRows: no rows in result set",
+		"### Breadcrumbs",
+		"[navigation] crumb-00 (to=/page/0)",
+		strings.Repeat("x", 200),
+		"header-11",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatEvent() markdown does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "more entries") || strings.Contains(got, "more value(s)") {
+		t.Errorf("formatEvent() markdown left out frames or values:\n%s", got)
+	}
+}
+
+func TestFormatEventList(t *testing.T) {
+	got := formatEventList([]*bugsnagAPI.Event{testEvent()}, formatSummary, "next-page", 30)
+	want := "Showing 1 of 30 events.\nNext cursor: `next-page`\n\n" +
+		"- `evt1` 2025-06-01T12:00:00Z *errors.errorString: nil pointer dereference · `GET /users` · v1.2.3 · user u1 (error `err1`)"
+	if got != want {
+		t.Errorf("formatEventList() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := formatEventList(nil, formatSummary, "", 0); got != "No events found." {
+		t.Errorf("formatEventList() without events = %q", got)
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		args          map[string]any
+		defaultFormat string
+		want          string
+		wantErr       bool
+	}{
+		{args: nil, want: formatSummary},
+		{args: nil, defaultFormat: formatJSON, want: formatJSON},
+		{args: map[string]any{"format": "summary"}, defaultFormat: formatJSON, want: formatSummary},
+		{args: map[string]any{"format": "json"}, want: formatJSON},
+		{args: map[string]any{"format": "markdown"}, want: formatMarkdown},
+		{args: map[string]any{"format": "yaml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args, tt.defaultFormat), func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			got, err := outputFormat(req, cmp.Or(tt.defaultFormat, formatSummary))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("outputFormat() = %q, %v, want %q, error %t", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest"
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnagtest/testconfig"
+)
+
+// callTool calls a tool handler with args, returning its text and whether it is an error.
+func callTool(t *testing.T, handler server.ToolHandlerFunc, args map[string]any) (string, bool) {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("tool call error = %v", err)
+	}
+	return strings.Join(resultTexts(result), "\n"), result.IsError
+}
+
+func TestToolHandlers(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+
+	tests := []struct {
+		name    string
+		handler server.ToolHandlerFunc
+		args    map[string]any
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "organizations",
+			handler: HandleGetUserOrganizationsTool(cfg),
+			want:    []string{`"name": "Acme"`, `"name": "Globex Corp"`, `"total_count": 2`},
+		},
+		{
+			name:    "projects by organization slug",
+			handler: HandleGetUserProjectsTool(cfg),
+			args:    map[string]any{"organization_id": "acme"},
+			want:    []string{`"slug": "web"`, `"slug": "api"`, `"total_count": 2`},
+		},
+		{
+			name:    "errors sorted by events",
+			handler: HandleListProjectErrorsTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "sort": "events", "per_page": 1},
+			want:    []string{bugsnagtest.NilPointerErrorID, `"total_count": 3`, `"next_cursor"`},
+		},
+		{
+			name:    "error by dashboard link",
+			handler: HandleGetProjectErrorTool(cfg),
+			args:    map[string]any{"project_id": "API", "error_id": "https://app.bugsnag.com/acme/api/errors/" + bugsnagtest.DeadlineErrorID},
+			want:    []string{`"error_class": "context.deadlineExceededError"`, `"status": "fixed"`},
+		},
+		{
+			name:    "event summary",
+			handler: HandleGetProjectEventTool(cfg),
+			args:    map[string]any{"project_id": bugsnagtest.APIProjectID, "event_id": bugsnagtest.NilPointerEventID},
+			want: []string{
+				"## runtime.Error: invalid memory address or nil pointer dereference",
+				"- **internal/users/store.go:57** `github.com/acme/api/internal/users.(*Store).Get`",
+				"  >   57 | \treturn u, row.Scan(&u.ID, &u.Name)",
+				"- _4 library frames (net/http)_",
+				"[request] GET /users/982",
+			},
+		},
+		{
+			name:    "event with recursion",
+			handler: HandleGetProjectEventTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "event_id": "https://app.bugsnag.com/acme/api/errors/" + bugsnagtest.DeadlineErrorID + "?event_id=8e0a1b2c3d4e5f6a7b8c9d05"},
+			want:    []string{"- _the previous 1 frame(s) repeated 3 more time(s)_"},
+		},
+		{
+			name:    "events filtered by version",
+			handler: HandleGetProjectEventsTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "app_version": "1.4.1", "format": "summary"},
+			want:    []string{bugsnagtest.NilPointerEventID, "Showing 3 of 3 events."},
+		},
+		{
+			name:    "events as JSON by default",
+			handler: HandleGetProjectEventsTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "per_page": 1},
+			want:    []string{`"items": [`, `"id": "` + bugsnagtest.NilPointerEventID + `"`, `"next_cursor"`, `"total_count": 5`},
+		},
+		{
+			name:    "error pivots",
+			handler: HandleGetErrorPivotsTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "error_id": bugsnagtest.NilPointerErrorID, "pivots": []any{"app.version"}},
+			want:    []string{`"event_field_display_id": "app.version"`, `"value": "1.4.1",` + "\n" + `          "events": "2"`, `"cardinality": 2`},
+		},
+		{
+			name:    "pivots of an unknown project",
+			handler: HandleGetErrorPivotsTool(cfg),
+			args:    map[string]any{"project_id": "acme/billing"},
+			want:    []string{"failed to resolve project"},
+			wantErr: true,
+		},
+		{
+			name:    "release by version and stage",
+			handler: HandleGetReleaseTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "release_id": "1.4.1", "release_stage": "production"},
+			want:    []string{`"id": "` + bugsnagtest.APIProductionReleaseID + `"`, `"total_sessions_count": 2500`},
+		},
+		{
+			name:    "release by ID",
+			handler: HandleGetReleaseTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "release_id": bugsnagtest.APIStagingReleaseID},
+			want:    []string{`"app_version": "1.4.1"`, `"name": "staging"`},
+		},
+		{
+			name:    "release version in several stages",
+			handler: HandleGetReleaseTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "release_id": "1.4.1"},
+			want:    []string{`version "1.4.1" matches 2 releases, set 'release_stage'`, bugsnagtest.APIStagingReleaseID + " (staging)", bugsnagtest.APIProductionReleaseID + " (production)"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown release version",
+			handler: HandleGetReleaseTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "release_id": "2.0.0"},
+			want:    []string{`no release of version "2.0.0"`},
+			wantErr: true,
+		},
+		{
+			name:    "release stability by versions",
+			handler: HandleGetReleaseStabilityTool(cfg),
+			args:    map[string]any{"project_id": "API", "release_ids": []any{"1.4.0", "1.4.1"}, "release_stage": "production"},
+			want:    []string{"| | 1.4.0 | 1.4.1 |", "| Crash-free sessions | 99.70% | 98.40% |"},
+		},
+		{
+			name:    "trend resolution without a unit",
+			handler: HandleGetErrorTrendTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "error_id": bugsnagtest.NilPointerErrorID, "resolution": "banana"},
+			want:    []string{`invalid 'resolution': invalid duration "banana"`},
+			wantErr: true,
+		},
+		{
+			name:    "empty trend resolution",
+			handler: HandleGetProjectTrendTool(cfg),
+			args:    map[string]any{"project_id": "acme/api", "resolution": "0h"},
+			want:    []string{`invalid 'resolution' "0h": must be longer than zero`},
+			wantErr: true,
+		},
+		{
+			name:    "unknown project",
+			handler: HandleListProjectErrorsTool(cfg),
+			args:    map[string]any{"project_id": "acme/billing"},
+			want:    []string{"failed to resolve project"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown event",
+			handler: HandleGetProjectEventTool(cfg),
+			args:    map[string]any{"project_id": "acme/web", "event_id": bugsnagtest.NilPointerEventID},
+			want:    []string{"Event could not be found"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isError := callTool(t, tt.handler, tt.args)
+			if isError != tt.wantErr {
+				t.Fatalf("tool result is error = %t, want %t:\n%s", isError, tt.wantErr, got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("tool result does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetProjectEventsRelativeSinceCursor(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	args := map[string]any{"project_id": "acme/api", "since": "500w", "per_page": 1}
+
+	// Each call resolves "500w" to a later timestamp
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	t.Cleanup(func() { timeNow = time.Now })
+
+	seen := map[string]bool{}
+	for page := 0; ; page++ {
+		got, isError := callTool(t, HandleGetProjectEventsTool(cfg), args)
+		if isError {
+			t.Fatalf("get_project_events page %d = %s, want events", page, got)
+		}
+		var result struct {
+			Items []struct {
+				ID string `json:"id"`
+			} `json:"items"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.Unmarshal([]byte(got), &result); err != nil {
+			t.Fatalf("get_project_events page %d = %s, want JSON: %v", page, got, err)
+		}
+		for _, item := range result.Items {
+			if seen[item.ID] {
+				t.Fatalf("event %s returned twice", item.ID)
+			}
+			seen[item.ID] = true
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		// Repeat the original arguments with the cursor, as the tool description asks
+		args["cursor"] = result.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Errorf("paged through %d events, want 5", len(seen))
+	}
+}
+
+func TestUpdateErrorStatusHandler(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	args := map[string]any{"project_id": "acme/api", "error_id": bugsnagtest.NilPointerErrorID, "status": "fixed", "confirm": true}
+
+	if got, isError := callTool(t, HandleUpdateErrorStatusTool(cfg), args); isError || !strings.Contains(got, `"status": "fixed"`) {
+		t.Fatalf("update_error_status = %s, want the error fixed", got)
+	}
+	got, _ := callTool(t, HandleGetProjectErrorTool(cfg), map[string]any{"project_id": "acme/api", "error_id": bugsnagtest.NilPointerErrorID, "cache_bypass": true})
+	if !strings.Contains(got, `"status": "fixed"`) {
+		t.Errorf("get_project_error after the update = %s, want the error fixed", got)
+	}
+}
+
+func TestBulkUpdateErrorsHandler(t *testing.T) {
+	cfg, fake := testconfig.NewFake(t)
+	args := map[string]any{"project_id": "acme/api", "status": "ignored", "filter": "error.status=open"}
+
+	got, isError := callTool(t, HandleBulkUpdateErrorsTool(cfg), args)
+	var dryRun bulkDryRun
+	if err := json.Unmarshal([]byte(got), &dryRun); isError || err != nil {
+		t.Fatalf("bulk_update_errors dry run = %s, want a dry run", got)
+	}
+	if len(dryRun.Errors) != 2 || dryRun.ConfirmationToken == "" {
+		t.Fatalf("dry run = %+v, want the 2 open errors and a confirmation token", dryRun)
+	}
+
+	// The first update fails, and is reported per error
+	fake.Fail(bugsnagtest.Fault{Method: http.MethodPatch, Status: http.StatusForbidden, Message: "Insufficient permissions", Times: 1})
+	args["confirmation_token"] = dryRun.ConfirmationToken
+	got, isError = callTool(t, HandleBulkUpdateErrorsTool(cfg), args)
+	if isError || !strings.Contains(got, "Insufficient permissions") || !strings.Contains(got, "\"succeeded\": 1,\n  \"failed\": 1") {
+		t.Errorf("bulk_update_errors = %s, want one error ignored and one failure", got)
+	}
+}
+
+func TestToolHandlersAPIFailure(t *testing.T) {
+	cfg, fake := testconfig.NewFake(t)
+	fake.Fail(bugsnagtest.Fault{Path: "/projects/" + bugsnagtest.WebProjectID + "/events", Status: http.StatusInternalServerError, Message: "Internal error"})
+
+	got, isError := callTool(t, HandleGetProjectEventsTool(cfg), map[string]any{"project_id": bugsnagtest.WebProjectID})
+	if !isError || !strings.Contains(got, "Internal error") {
+		t.Errorf("get_project_events = %s, want the API failure", got)
+	}
+}
+
+func TestOpenBugsnagLinkHandler(t *testing.T) {
+	cfg, _ := testconfig.NewFake(t)
+	const olderEventID = "8e0a1b2c3d4e5f6a7b8c9d03"
+
+	tests := []struct {
+		name    string
+		link    string
+		want    []string
+		wantNot []string
+		wantErr bool
+	}{
+		{
+			name:    "errors with filters",
+			link:    "https://app.bugsnag.com/acme/api/errors?filters[error.status][0]=open",
+			want:    []string{bugsnagtest.NilPointerErrorID, `"filters": "error.status=open"`},
+			wantNot: []string{bugsnagtest.DeadlineErrorID},
+		},
+		{
+			name:    "project with filters",
+			link:    "https://app.bugsnag.com/acme/api/overview?filters[error.status]=open",
+			want:    []string{`"project": {`, `"slug": "api"`, bugsnagtest.NilPointerErrorID},
+			wantNot: []string{bugsnagtest.DeadlineErrorID},
+		},
+		{
+			name:    "error with filters",
+			link:    "https://app.bugsnag.com/acme/api/errors/" + bugsnagtest.NilPointerErrorID + "?filters[app.version]=1.4.0",
+			want:    []string{`"error": {`, `"events": {`, olderEventID, `"filters": "app.version=1.4.0"`},
+			wantNot: []string{bugsnagtest.NilPointerEventID},
+		},
+		{
+			name: "API event link with filters",
+			link: "https://api.bugsnag.com/projects/" + bugsnagtest.APIProjectID + "/events/" + bugsnagtest.NilPointerEventID + "?filters[app.version]=1.4.0",
+			want: []string{`"project_id": "` + bugsnagtest.APIProjectID + `"`, `"event": {`, olderEventID},
+		},
+		{
+			name:    "error without filters",
+			link:    "https://app.bugsnag.com/acme/api/errors/" + bugsnagtest.NilPointerErrorID,
+			want:    []string{`"error_class": "runtime.Error"`},
+			wantNot: []string{`"events": {`, `"filters"`},
+		},
+		{
+			name:    "release with filters",
+			link:    "https://app.bugsnag.com/acme/api/releases/r1?filters[app.version]=1.4.0",
+			want:    []string{"cannot be applied to release links"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isError := callTool(t, HandleOpenBugsnagLinkTool(cfg), map[string]any{"link": tt.link})
+			if isError != tt.wantErr {
+				t.Fatalf("tool result is error = %t, want %t:\n%s", isError, tt.wantErr, got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("tool result does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.wantNot {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("tool result contains %q:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+// openLinkResult is the result of opening a dashboard link.
+type openLinkResult struct {
+	Link *bugsnag.Link `json:"link"`
+	// Filters are the filters that were applied, as a filter expression.
+	Filters string `json:"filters,omitempty"`
+	Result  any    `json:"result"`
+}
+
+// NewOpenBugsnagLinkTool returns the MCP tool for retrieving whatever a Bugsnag dashboard link points to.
+func NewOpenBugsnagLinkTool() mcp.Tool {
+	return mcp.NewTool(
+		OpenBugsnagLinkToolID,
+		mcp.WithDescription("Retrieves whatever a Bugsnag dashboard link points to: an organization, project, error, event, release, "+
+			"list of releases, or list of errors, applying the filters and saved search embedded in the link: to the errors of a project or list, "+
+			"and to the events of an error or event. Use it when given a link, e.g. pasted from Slack"),
+		mcp.WithString(
+			"link",
+			mcp.Required(),
+			mcp.Description("The Bugsnag dashboard link, e.g. https://app.bugsnag.com/{organization}/{project}/errors/{error_id}?event_id={event_id}"),
+		),
+		withCacheBypass(),
+	)
+}
+
+// HandleOpenBugsnagLinkTool handles the tool call to retrieve whatever a Bugsnag dashboard link points to.
+func HandleOpenBugsnagLinkTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		raw, err := req.RequireString("link")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'link': %v", err)), nil
+		}
+		link, err := bugsnag.ParseLink(raw)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, filters, err := openLink(ctx, cfg, link)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to open %s link: %v", link.Kind, err)), nil
+		}
+
+		resultJSON, err := json.MarshalIndent(openLinkResult{
+			Link:    link,
+			Filters: bugsnag.FormatFilters(filters),
+			Result:  result,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal link result: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+}
+
+// projectLinkResult is a project opened from a link with filters, along with its errors matching them.
+type projectLinkResult struct {
+	Project *bugsnagAPI.Project           `json:"project"`
+	Errors  listResult[*bugsnagAPI.Error] `json:"errors"`
+}
+
+// errorLinkResult is an error or event opened from a link with filters, along with the events of the error
+// matching them.
+type errorLinkResult struct {
+	Error  *bugsnagAPI.Error             `json:"error,omitempty"`
+	Event  *bugsnagAPI.Event             `json:"event,omitempty"`
+	Events listResult[*bugsnagAPI.Event] `json:"events"`
+}
+
+// openLink retrieves the entity a link points to, along with the filters applied to retrieve it.
+// The filters of a link select the errors of a project, and the events of an error.
+func openLink(ctx context.Context, cfg *config.Config, link *bugsnag.Link) (any, []bugsnagAPI.Filter, error) {
+	if link.Kind == bugsnag.LinkOrganization {
+		if len(link.Filters) > 0 {
+			return nil, nil, unappliedFilters(link)
+		}
+		orgID, err := cfg.Resolver(ctx).ResolveOrganization(ctx, cmp.Or(link.OrganizationID, link.OrganizationSlug))
+		if err != nil {
+			return nil, nil, err
+		}
+		var org bugsnagAPI.Organization
+		if err := bugsnag.Get(ctx, cfg.Client(ctx), "organizations/"+url.PathEscape(orgID), &org); err != nil {
+			return nil, nil, err
+		}
+		return &org, nil, nil
+	}
+
+	projectID, err := cfg.Resolver(ctx).ResolveProject(ctx, cmp.Or(link.ProjectID, link.OrganizationSlug+"/"+link.ProjectSlug))
+	if err != nil {
+		return nil, nil, err
+	}
+	errorsPath := "projects/" + url.PathEscape(projectID) + "/errors"
+
+	switch link.Kind {
+	case bugsnag.LinkError:
+		bugsnagErr, _, err := cfg.Client(ctx).Errors.GetError(ctx, projectID, link.ErrorID)
+		if err != nil || len(link.Filters) == 0 {
+			return bugsnagErr, nil, err
+		}
+		events, err := listLinkEvents(ctx, cfg, errorsPath+"/"+url.PathEscape(link.ErrorID)+"/events", link.Filters)
+		return errorLinkResult{Error: bugsnagErr, Events: events}, link.Filters, err
+	case bugsnag.LinkEvent:
+		event, _, err := cfg.Client(ctx).Events.GetEvent(ctx, projectID, link.EventID)
+		if err != nil || len(link.Filters) == 0 {
+			return event, nil, err
+		}
+		errorID := cmp.Or(link.ErrorID, event.ErrorID)
+		events, err := listLinkEvents(ctx, cfg, errorsPath+"/"+url.PathEscape(errorID)+"/events", link.Filters)
+		return errorLinkResult{Event: event, Events: events}, link.Filters, err
+	case bugsnag.LinkErrors:
+		errs, err := listLinkErrors(ctx, cfg, errorsPath, link.Filters)
+		return errs, link.Filters, err
+	case bugsnag.LinkSavedSearch:
+		var search bugsnag.SavedSearch
+		if err := bugsnag.Get(ctx, cfg.Client(ctx), "saved_searches/"+url.PathEscape(link.SavedSearchID), &search); err != nil {
+			return nil, nil, err
+		}
+		filters := append(search.FilterList(), link.Filters...)
+		errs, err := listLinkErrors(ctx, cfg, errorsPath, filters)
+		return errs, filters, err
+	case bugsnag.LinkReleases:
+		if len(link.Filters) > 0 {
+			return nil, nil, unappliedFilters(link)
+		}
+		releases, page, err := bugsnag.ListPage[*bugsnag.Release](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/releases", nil, bugsnag.PageOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return listResult[*bugsnag.Release]{Items: releases, PageInfo: *page}, nil, nil
+	case bugsnag.LinkRelease:
+		if len(link.Filters) > 0 {
+			return nil, nil, unappliedFilters(link)
+		}
+		release, err := getRelease(ctx, cfg, link.ReleaseID)
+		return release, nil, err
+	default:
+		project, _, err := cfg.Client(ctx).Projects.GetProject(ctx, projectID)
+		if err != nil || len(link.Filters) == 0 {
+			return project, nil, err
+		}
+		errs, err := listLinkErrors(ctx, cfg, errorsPath, link.Filters)
+		return projectLinkResult{Project: project, Errors: errs}, link.Filters, err
+	}
+}
+
+// listLinkErrors retrieves the first page of errors in a project matching the filters of a link.
+func listLinkErrors(ctx context.Context, cfg *config.Config, path string, filters []bugsnagAPI.Filter) (listResult[*bugsnagAPI.Error], error) {
+	bugsnagErrors, page, err := bugsnag.ListPage[*bugsnagAPI.Error](ctx, cfg.Client(ctx), path, bugsnag.EncodeFilters(filters), bugsnag.PageOptions{})
+	if err != nil {
+		return listResult[*bugsnagAPI.Error]{}, err
+	}
+	return listResult[*bugsnagAPI.Error]{Items: bugsnagErrors, PageInfo: *page}, nil
+}
+
+// listLinkEvents retrieves the first page of events of an error matching the filters of a link.
+func listLinkEvents(ctx context.Context, cfg *config.Config, path string, filters []bugsnagAPI.Filter) (listResult[*bugsnagAPI.Event], error) {
+	events, page, err := bugsnag.ListPage[*bugsnagAPI.Event](ctx, cfg.Client(ctx), path, bugsnag.EncodeFilters(filters), bugsnag.PageOptions{})
+	if err != nil {
+		return listResult[*bugsnagAPI.Event]{}, err
+	}
+	return listResult[*bugsnagAPI.Event]{Items: events, PageInfo: *page}, nil
+}
+
+// unappliedFilters returns the error for a link whose filters cannot be applied to what it points to.
+func unappliedFilters(link *bugsnag.Link) error {
+	return fmt.Errorf("the filters %s cannot be applied to %s links; remove them from the link", bugsnag.FormatFilters(link.Filters), link.Kind)
+}
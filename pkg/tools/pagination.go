@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// listResult is the result of a paginated list tool.
+type listResult[T any] struct {
+	Items []T `json:"items"`
+	bugsnag.PageInfo
+}
+
+// withPagination returns the tool options for the arguments used to page through a list.
+func withPagination() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithNumber(
+			"per_page",
+			mcp.Description(fmt.Sprintf("The number of items to retrieve per page (1-%d)", bugsnag.MaxPerPage)),
+			mcp.Min(1),
+			mcp.Max(bugsnag.MaxPerPage),
+		),
+		mcp.WithString(
+			"cursor",
+			mcp.Description("The 'next_cursor' returned by a previous call, to retrieve the next page. The filter and sort arguments are carried by the cursor: leave them out or repeat them unchanged"),
+		),
+		mcp.WithNumber(
+			"max_items",
+			mcp.Description(fmt.Sprintf("Automatically follow pages until this many items have been retrieved (at most %d). Without it a single page is returned", bugsnag.MaxItemsLimit)),
+			mcp.Min(1),
+			mcp.Max(bugsnag.MaxItemsLimit),
+		),
+	}
+}
+
+// pageOptions builds the page options from the pagination tool arguments.
+func pageOptions(req mcp.CallToolRequest) bugsnag.PageOptions {
+	return bugsnag.PageOptions{
+		PerPage:  req.GetInt("per_page", 0),
+		Cursor:   req.GetString("cursor", ""),
+		MaxItems: req.GetInt("max_items", 0),
+	}
+}
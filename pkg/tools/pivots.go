@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+// defaultPivotSummarySize is the number of top values returned per pivot when not specified.
+const defaultPivotSummarySize = 10
+
+// NewGetErrorPivotsTool returns the MCP tool for breaking down the events of an error or project by event field.
+func NewGetErrorPivotsTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Retrieves the pivot breakdowns (top values and event counts per field, e.g. app versions, OSes, browsers, users, contexts or custom fields) " +
+			"for an error, or for all events in a project matching the filters when no error is given"),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve pivots for"),
+		),
+		mcp.WithString(
+			"error_id",
+			mcp.Description("The ID/url of the error to retrieve pivots for. Omit to break down all events in the project"),
+		),
+		mcp.WithArray(
+			"pivots",
+			mcp.Description("The event fields to break down by, e.g. app.version, device.osName, device.browserName, user.id, context or a custom event field. Defaults to all pivots"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber(
+			"summary_size",
+			mcp.Description(fmt.Sprintf("The number of top values to return per pivot (default %d)", defaultPivotSummarySize)),
+			mcp.Min(1),
+		),
+	}
+	opts = append(opts, withEventFilters()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(GetErrorPivotsToolID, opts...)
+}
+
+// HandleGetErrorPivotsTool handles the tool call to retrieve the pivot breakdowns for an error or project.
+func HandleGetErrorPivotsTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+
+		path := "projects/" + url.PathEscape(projectID) + "/pivots"
+		if reqParam := req.GetString("error_id", ""); reqParam != "" {
+			errorID, err := bugsnag.ErrorIDFromIDOrLink(reqParam)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid error ID or link: %v", err)), nil
+			}
+			path = "projects/" + url.PathEscape(projectID) + "/errors/" + url.PathEscape(errorID) + "/pivots"
+		}
+
+		filters, err := eventFilters(req, timeNow())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		query := pivotsQuery(req.GetStringSlice("pivots", nil), req.GetInt("summary_size", defaultPivotSummarySize), filters)
+
+		var pivots []*bugsnagAPI.Pivot
+		if _, err := bugsnag.List(ctx, cfg.Client(ctx), path, query, &pivots); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve pivots: %v", err)), nil
+		}
+
+		pivotsJSON, err := json.MarshalIndent(pivots, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal pivots: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(pivotsJSON)), nil
+	}
+}
+
+// pivotsQuery builds the query parameters for a pivots request.
+func pivotsQuery(pivots []string, summarySize int, filters []bugsnagAPI.Filter) url.Values {
+	query := bugsnag.EncodeFilters(filters)
+	if summarySize > 0 {
+		query.Set("summary_size", strconv.Itoa(summarySize))
+	}
+	for _, pivot := range pivots {
+		if pivot != "" {
+			query.Add("pivots[]", pivot)
+		}
+	}
+	return query
+}
@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"testing"
+
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+)
+
+func TestPivotsQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		pivots      []string
+		summarySize int
+		filters     []bugsnagAPI.Filter
+		want        string
+	}{
+		{
+			name:        "all pivots",
+			summarySize: 10,
+			want:        "summary_size=10",
+		},
+		{
+			name:        "selected pivots",
+			pivots:      []string{"app.version", "", "user.id"},
+			summarySize: 5,
+			want:        "pivots%5B%5D=app.version&pivots%5B%5D=user.id&summary_size=5",
+		},
+		{
+			name:    "with filters",
+			pivots:  []string{"device.osName"},
+			filters: []bugsnagAPI.Filter{{Key: "app.release_stage", Type: "eq", Value: "production"}},
+			want:    "filters%5Bapp.release_stage%5D%5B%5D%5Btype%5D=eq&filters%5Bapp.release_stage%5D%5B%5D%5Bvalue%5D=production&pivots%5B%5D=device.osName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pivotsQuery(tt.pivots, tt.summarySize, tt.filters).Encode()
+			if got != tt.want {
+				t.Errorf("pivotsQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
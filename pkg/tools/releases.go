@@ -0,0 +1,343 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+// maxStabilityReleases is the maximum number of releases that can be compared at once.
+const maxStabilityReleases = 10
+
+// releaseStability is the stability of a single release.
+type releaseStability struct {
+	ID                       string    `json:"id"`
+	AppVersion               string    `json:"app_version"`
+	ReleaseStage             string    `json:"release_stage"`
+	ReleaseTime              time.Time `json:"release_time"`
+	Revision                 string    `json:"revision,omitempty"`
+	TotalSessions            int       `json:"total_sessions"`
+	UnhandledSessions        int       `json:"unhandled_sessions"`
+	SessionsInLast24h        int       `json:"sessions_in_last_24h"`
+	Users                    int       `json:"users"`
+	UsersWithUnhandled       int       `json:"users_with_unhandled"`
+	ErrorsIntroduced         int       `json:"errors_introduced"`
+	ErrorsSeen               int       `json:"errors_seen"`
+	CrashFreeSessionsPercent *float64  `json:"crash_free_sessions_percent,omitempty"`
+	CrashFreeUsersPercent    *float64  `json:"crash_free_users_percent,omitempty"`
+}
+
+// stabilityResult is the result of the release stability tool.
+type stabilityResult struct {
+	Comparison string             `json:"comparison"`
+	Releases   []releaseStability `json:"releases"`
+}
+
+// NewListReleasesTool returns the MCP tool for listing the releases of a project.
+func NewListReleasesTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Retrieves the releases for a project from Bugsnag, including version, release stage, build time, source control revision and session counts"),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve releases for"),
+		),
+		mcp.WithString(
+			"release_stage",
+			mcp.Description("Only include releases deployed to this release stage, e.g. production"),
+		),
+	}
+	opts = append(opts, withPagination()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(ListReleasesToolID, opts...)
+}
+
+// HandleListReleasesTool handles the tool call to retrieve the releases for a given project.
+func HandleListReleasesTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+
+		query := url.Values{}
+		if stage := req.GetString("release_stage", ""); stage != "" {
+			query.Set("release_stage", stage)
+		}
+
+		releases, page, err := bugsnag.ListPage[*bugsnag.Release](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/releases", query, pageOptions(req))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve releases: %v", err)), nil
+		}
+
+		releasesJSON, err := json.MarshalIndent(listResult[*bugsnag.Release]{Items: releases, PageInfo: *page}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal releases: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(releasesJSON)), nil
+	}
+}
+
+// withReleaseProject returns the tool options for the project the releases of a tool belong to, and the
+// release stage narrowing down app versions.
+func withReleaseProject() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project the releases belong to"),
+		),
+		mcp.WithString(
+			"release_stage",
+			mcp.Description("The release stage of the releases given by app version, e.g. production, when the version was released to several stages"),
+		),
+	}
+}
+
+// NewGetReleaseTool returns the MCP tool for retrieving a specific release.
+func NewGetReleaseTool() mcp.Tool {
+	opts := withReleaseProject()
+	opts = append(opts,
+		mcp.WithDescription("Retrieves a specific release of a project from Bugsnag, by app version or ID"),
+		mcp.WithString(
+			"release_id",
+			mcp.Required(),
+			mcp.Description("The app version, e.g. 1.4.1, or the ID of the release to retrieve"),
+		),
+		withCacheBypass(),
+	)
+	return mcp.NewTool(GetReleaseToolID, opts...)
+}
+
+// HandleGetReleaseTool handles the tool call to retrieve a specific release by app version or ID.
+func HandleGetReleaseTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+		releaseRef, err := req.RequireString("release_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'release_id': %v", err)), nil
+		}
+
+		release, err := resolveRelease(ctx, cfg, projectID, releaseRef, req.GetString("release_stage", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve release: %v", err)), nil
+		}
+
+		releaseJSON, err := json.MarshalIndent(release, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal release: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(releaseJSON)), nil
+	}
+}
+
+// NewGetReleaseStabilityTool returns the MCP tool for retrieving and comparing the stability of releases.
+func NewGetReleaseStabilityTool() mcp.Tool {
+	opts := withReleaseProject()
+	opts = append(opts,
+		mcp.WithDescription("Retrieves the stability of one or more releases of a project (crash-free sessions and users, session and user counts, errors introduced and seen) "+
+			"and renders them side by side, so two releases can be compared"),
+		mcp.WithArray(
+			"release_ids",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("The app versions, e.g. 1.4.1, or IDs of the releases to retrieve the stability for (at most %d)", maxStabilityReleases)),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxStabilityReleases),
+		),
+		withCacheBypass(),
+	)
+	return mcp.NewTool(GetReleaseStabilityToolID, opts...)
+}
+
+// HandleGetReleaseStabilityTool handles the tool call to retrieve and compare the stability of releases.
+func HandleGetReleaseStabilityTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+		releaseRefs, err := req.RequireStringSlice("release_ids")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'release_ids': %v", err)), nil
+		}
+		if len(releaseRefs) == 0 || len(releaseRefs) > maxStabilityReleases {
+			return mcp.NewToolResultError(fmt.Sprintf("'release_ids' must contain between 1 and %d releases", maxStabilityReleases)), nil
+		}
+
+		stage := req.GetString("release_stage", "")
+		result := stabilityResult{Releases: make([]releaseStability, 0, len(releaseRefs))}
+		for _, releaseRef := range releaseRefs {
+			release, err := resolveRelease(ctx, cfg, projectID, releaseRef, stage)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve release %s: %v", releaseRef, err)), nil
+			}
+			result.Releases = append(result.Releases, newReleaseStability(release))
+		}
+		result.Comparison = formatStabilityTable(result.Releases)
+
+		stabilityJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal release stability: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(stabilityJSON)), nil
+	}
+}
+
+// getRelease retrieves a release by ID.
+func getRelease(ctx context.Context, cfg *config.Config, releaseID string) (*bugsnag.Release, error) {
+	var release bugsnag.Release
+	if err := bugsnag.Get(ctx, cfg.Client(ctx), "releases/"+url.PathEscape(releaseID), &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// resolveRelease returns the release of a project referred to by an ID or an app version. Versions are
+// looked up among the releases of the project, of the given release stage if any, and must match a single one.
+func resolveRelease(ctx context.Context, cfg *config.Config, projectID, ref, stage string) (*bugsnag.Release, error) {
+	ref = strings.TrimSpace(ref)
+	if bugsnag.IsID(ref) {
+		return getRelease(ctx, cfg, ref)
+	}
+
+	query := url.Values{}
+	if stage != "" {
+		query.Set("release_stage", stage)
+	}
+	releases, _, err := bugsnag.ListPage[*bugsnag.Release](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/releases", query, bugsnag.PageOptions{
+		MaxItems: bugsnag.MaxItemsLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var matches []*bugsnag.Release
+	for _, release := range releases {
+		if release.AppVersion == ref {
+			matches = append(matches, release)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if stage != "" {
+			return nil, fmt.Errorf("no release of version %q in release stage %q", ref, stage)
+		}
+		return nil, fmt.Errorf("no release of version %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		candidates := make([]string, 0, len(matches))
+		for _, release := range matches {
+			candidates = append(candidates, fmt.Sprintf("%s (%s)", release.ID, release.ReleaseStage.Name))
+		}
+		return nil, fmt.Errorf("version %q matches %d releases, set 'release_stage' or use one of the IDs: %s", ref, len(matches), strings.Join(candidates, "; "))
+	}
+}
+
+// newReleaseStability computes the stability of a release from its session and user counts.
+func newReleaseStability(r *bugsnag.Release) releaseStability {
+	s := releaseStability{
+		ID:                 r.ID,
+		AppVersion:         r.AppVersion,
+		ReleaseStage:       r.ReleaseStage.Name,
+		ReleaseTime:        r.ReleaseTime,
+		TotalSessions:      r.TotalSessionsCount,
+		UnhandledSessions:  r.UnhandledSessionsCount,
+		SessionsInLast24h:  r.SessionsCountInLast24h,
+		Users:              r.AccumulativeDailyUsersSeen,
+		UsersWithUnhandled: r.AccumulativeDailyUsersWithUnhandled,
+		ErrorsIntroduced:   r.ErrorsIntroducedCount,
+		ErrorsSeen:         r.ErrorsSeenCount,
+	}
+	if r.SourceControl != nil {
+		s.Revision = r.SourceControl.Revision
+	}
+	s.CrashFreeSessionsPercent = crashFreePercent(r.UnhandledSessionsCount, r.TotalSessionsCount)
+	s.CrashFreeUsersPercent = crashFreePercent(r.AccumulativeDailyUsersWithUnhandled, r.AccumulativeDailyUsersSeen)
+	return s
+}
+
+// crashFreePercent returns the percentage of the total that did not crash, rounded to two decimals.
+// It returns nil when there is no data to compute it from.
+func crashFreePercent(unhandled, total int) *float64 {
+	if total <= 0 {
+		return nil
+	}
+	percent := math.Round((1-float64(unhandled)/float64(total))*10000) / 100
+	return &percent
+}
+
+// formatStabilityTable renders the stability of the releases side by side as a Markdown table.
+func formatStabilityTable(releases []releaseStability) string {
+	rows := []struct {
+		Label string
+		Value func(releaseStability) string
+	}{
+		{"Release stage", func(s releaseStability) string { return s.ReleaseStage }},
+		{"Released", func(s releaseStability) string { return s.ReleaseTime.UTC().Format(time.RFC3339) }},
+		{"Revision", func(s releaseStability) string { return s.Revision }},
+		{"Crash-free sessions", func(s releaseStability) string { return formatPercent(s.CrashFreeSessionsPercent) }},
+		{"Crash-free users", func(s releaseStability) string { return formatPercent(s.CrashFreeUsersPercent) }},
+		{"Sessions (total)", func(s releaseStability) string { return fmt.Sprint(s.TotalSessions) }},
+		{"Sessions (last 24h)", func(s releaseStability) string { return fmt.Sprint(s.SessionsInLast24h) }},
+		{"Unhandled sessions", func(s releaseStability) string { return fmt.Sprint(s.UnhandledSessions) }},
+		{"Users", func(s releaseStability) string { return fmt.Sprint(s.Users) }},
+		{"Errors introduced", func(s releaseStability) string { return fmt.Sprint(s.ErrorsIntroduced) }},
+		{"Errors seen", func(s releaseStability) string { return fmt.Sprint(s.ErrorsSeen) }},
+	}
+
+	var b strings.Builder
+	b.WriteString("| |")
+	for _, r := range releases {
+		b.WriteString(" " + r.AppVersion + " |")
+	}
+	b.WriteString("\n|---|")
+	b.WriteString(strings.Repeat("---|", len(releases)))
+	for _, row := range rows {
+		b.WriteString("\n| " + row.Label + " |")
+		for _, r := range releases {
+			b.WriteString(" " + row.Value(r) + " |")
+		}
+	}
+	return b.String()
+}
+
+// formatPercent renders an optional percentage, using n/a when it is unknown.
+func formatPercent(percent *float64) string {
+	if percent == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%%", *percent)
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+func TestCrashFreePercent(t *testing.T) {
+	tests := []struct {
+		name      string
+		unhandled int
+		total     int
+		want      *float64
+	}{
+		{name: "no crashes", unhandled: 0, total: 200, want: ptr(100.0)},
+		{name: "some crashes", unhandled: 3, total: 400, want: ptr(99.25)},
+		{name: "rounded to two decimals", unhandled: 1, total: 3, want: ptr(66.67)},
+		{name: "no sessions", unhandled: 0, total: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := crashFreePercent(tt.unhandled, tt.total)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("crashFreePercent(%d, %d) = %v, want %v", tt.unhandled, tt.total, formatPercent(got), formatPercent(tt.want))
+			}
+		})
+	}
+}
+
+func TestNewReleaseStability(t *testing.T) {
+	release := &bugsnag.Release{
+		ID:                     "r1",
+		AppVersion:             "1.2.0",
+		ReleaseTime:            time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		TotalSessionsCount:     1000,
+		UnhandledSessionsCount: 25,
+		ErrorsIntroducedCount:  2,
+		ReleaseStage:           bugsnag.ReleaseStage{Name: "production"},
+		SourceControl:          &bugsnag.SourceControl{Revision: "abc123"},
+	}
+
+	got := newReleaseStability(release)
+	if got.ReleaseStage != "production" || got.Revision != "abc123" || got.ErrorsIntroduced != 2 {
+		t.Errorf("newReleaseStability() = %+v, want stage, revision and errors copied from the release", got)
+	}
+	if got.CrashFreeSessionsPercent == nil || *got.CrashFreeSessionsPercent != 97.5 {
+		t.Errorf("CrashFreeSessionsPercent = %v, want 97.50%%", formatPercent(got.CrashFreeSessionsPercent))
+	}
+	if got.CrashFreeUsersPercent != nil {
+		t.Errorf("CrashFreeUsersPercent = %v, want unset without user data", formatPercent(got.CrashFreeUsersPercent))
+	}
+}
+
+func TestFormatStabilityTable(t *testing.T) {
+	releaseTime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	table := formatStabilityTable([]releaseStability{
+		{AppVersion: "1.1.0", ReleaseStage: "production", ReleaseTime: releaseTime, TotalSessions: 400, CrashFreeSessionsPercent: ptr(99.25)},
+		{AppVersion: "1.2.0", ReleaseStage: "production", ReleaseTime: releaseTime, TotalSessions: 1000, CrashFreeSessionsPercent: ptr(97.5)},
+	})
+
+	lines := strings.Split(table, "\n")
+	wantLines := []string{
+		"| | 1.1.0 | 1.2.0 |",
+		"|---|---|---|",
+		"| Release stage | production | production |",
+		"| Released | 2025-06-01T12:00:00Z | 2025-06-01T12:00:00Z |",
+	}
+	for i, want := range wantLines {
+		if lines[i] != want {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+	for _, want := range []string{
+		"| Crash-free sessions | 99.25% | 97.50% |",
+		"| Crash-free users | n/a | n/a |",
+		"| Sessions (total) | 400 | 1000 |",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table does not contain %q:\n%s", want, table)
+		}
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
 
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
 	"github.com/sazap10/bugsnag-mcp/pkg/config"
 )
 
@@ -19,25 +20,49 @@ const (
 	GetUserProjectsToolID      = "get_user_projects"
 	GetProjectEventToolID      = "get_project_event"
 	GetProjectEventsToolID     = "get_project_events"
+	ListProjectErrorsToolID    = "list_project_errors"
+	GetProjectErrorToolID      = "get_project_error"
+	UpdateErrorStatusToolID    = "update_error_status"
+	BulkUpdateErrorsToolID     = "bulk_update_errors"
+	GetErrorPivotsToolID       = "get_error_pivots"
+	GetErrorTrendToolID        = "get_error_trend"
+	GetProjectTrendToolID      = "get_project_trend"
+	ListReleasesToolID         = "list_releases"
+	GetReleaseToolID           = "get_release"
+	GetReleaseStabilityToolID  = "get_release_stability"
+	OpenBugsnagLinkToolID      = "open_bugsnag_link"
 )
 
+// Mutates reports whether the tool with the given ID changes data in Bugsnag.
+func Mutates(toolID string) bool {
+	switch toolID {
+	case UpdateErrorStatusToolID, BulkUpdateErrorsToolID:
+		return true
+	default:
+		return false
+	}
+}
+
 // NewGetUserOrganizationsTool returns the MCP tool for listing Bugsnag organizations for the current user.
 func NewGetUserOrganizationsTool() mcp.Tool {
-	return mcp.NewTool(
-		GetUserOrganizationsToolID,
+	opts := []mcp.ToolOption{
 		mcp.WithDescription("Retrieves the organizations for the current user from Bugsnag"),
-	)
+	}
+	opts = append(opts, withPagination()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(GetUserOrganizationsToolID, opts...)
 }
 
 // HandleGetUserOrganizationsTool handles the tool call to retrieve all organizations for the current user.
 func HandleGetUserOrganizationsTool(cfg *config.Config) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		orgs, _, err := cfg.APIClient.CurrentUser.ListOrganizations(ctx, nil)
+		ctx = cacheContext(ctx, req)
+		orgs, page, err := bugsnag.ListPage[*bugsnagAPI.Organization](ctx, cfg.Client(ctx), "user/organizations", nil, pageOptions(req))
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve organizations: %v", err)), nil
 		}
 
-		orgsJSON, err := json.MarshalIndent(orgs, "", "  ")
+		orgsJSON, err := json.MarshalIndent(listResult[*bugsnagAPI.Organization]{Items: orgs, PageInfo: *page}, "", "  ")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal organizations: %v", err)), nil
 		}
@@ -48,30 +73,37 @@ func HandleGetUserOrganizationsTool(cfg *config.Config) server.ToolHandlerFunc {
 
 // NewGetUserProjectsTool returns the MCP tool for listing all projects in a specified organization.
 func NewGetUserProjectsTool() mcp.Tool {
-	return mcp.NewTool(
-		GetUserProjectsToolID,
+	opts := []mcp.ToolOption{
 		mcp.WithDescription("Retrieves the projects for the current user from Bugsnag"),
 		mcp.WithString(
 			"organization_id",
 			mcp.Required(),
-			mcp.Description("The ID of the organization to retrieve projects for"),
+			mcp.Description("The ID, slug, name or dashboard URL of the organization to retrieve projects for"),
 		),
-	)
+	}
+	opts = append(opts, withPagination()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(GetUserProjectsToolID, opts...)
 }
 
 // HandleGetUserProjectsTool handles the tool call to retrieve all projects for a given organization.
 func HandleGetUserProjectsTool(cfg *config.Config) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
 		org_id, err := req.RequireString("organization_id")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'organization_id': %v", err)), nil
 		}
-		projects, _, err := cfg.APIClient.CurrentUser.ListProjects(ctx, org_id, nil)
+		org_id, err = cfg.Resolver(ctx).ResolveOrganization(ctx, org_id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve organization: %v", err)), nil
+		}
+		projects, page, err := bugsnag.ListPage[*bugsnagAPI.Project](ctx, cfg.Client(ctx), "organizations/"+url.PathEscape(org_id)+"/projects", nil, pageOptions(req))
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve projects: %v", err)), nil
 		}
 
-		projectsJSON, err := json.MarshalIndent(projects, "", "  ")
+		projectsJSON, err := json.MarshalIndent(listResult[*bugsnagAPI.Project]{Items: projects, PageInfo: *page}, "", "  ")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal projects: %v", err)), nil
 		}
@@ -88,23 +120,30 @@ func NewGetProjectEventTool() mcp.Tool {
 		mcp.WithString(
 			"project_id",
 			mcp.Required(),
-			mcp.Description("The ID of the project to retrieve the event for"),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve the event for"),
 		),
 		mcp.WithString(
 			"event_id",
 			mcp.Required(),
 			mcp.Description("The ID/url of the event to retrieve"),
 		),
+		withFormat(formatSummary),
+		withCacheBypass(),
 	)
 }
 
 // HandleGetProjectEventTool handles the tool call to retrieve a specific event for a project by event ID or link.
 func HandleGetProjectEventTool(cfg *config.Config) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
 		projectID, err := req.RequireString("project_id")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
 		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
 		reqParam, err := req.RequireString("event_id")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'event_id': %v", err)), nil
@@ -114,11 +153,18 @@ func HandleGetProjectEventTool(cfg *config.Config) server.ToolHandlerFunc {
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("invalid event ID or link: %v", err)), nil
 		}
+		format, err := outputFormat(req, formatSummary)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
-		event, _, err := cfg.APIClient.Events.GetEvent(ctx, projectID, eventID)
+		event, _, err := cfg.Client(ctx).Events.GetEvent(ctx, projectID, eventID)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve event: %v", err)), nil
 		}
+		if format != formatJSON {
+			return mcp.NewToolResultText(formatEvent(event, format)), nil
+		}
 
 		eventJSON, err := json.MarshalIndent(event, "", "  ")
 		if err != nil {
@@ -131,32 +177,52 @@ func HandleGetProjectEventTool(cfg *config.Config) server.ToolHandlerFunc {
 
 // NewGetProjectEventsTool returns the MCP tool for listing all events for a project.
 func NewGetProjectEventsTool() mcp.Tool {
-	return mcp.NewTool(
-		GetProjectEventsToolID,
-		mcp.WithDescription("Retrieves all events for a project from Bugsnag"),
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Retrieves the events for a project from Bugsnag, optionally filtered by time window, release stage, app version, severity, error class, user or any other Bugsnag filter field"),
 		mcp.WithString(
 			"project_id",
 			mcp.Required(),
-			mcp.Description("The ID of the project to retrieve events for"),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve events for"),
 		),
-	)
+	}
+	opts = append(opts, withEventFilters()...)
+	opts = append(opts, withPagination()...)
+	opts = append(opts, withFormat(formatJSON), withCacheBypass())
+	return mcp.NewTool(GetProjectEventsToolID, opts...)
 }
 
 // HandleGetProjectEventsTool handles the tool call to retrieve all events for a given project.
 func HandleGetProjectEventsTool(cfg *config.Config) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
 		projectID, err := req.RequireString("project_id")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
 		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+
+		filters, err := eventFilters(req, timeNow())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		format, err := outputFormat(req, formatJSON)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
 		// Fetch events for the project
-		events, _, err := cfg.APIClient.Events.ListProjectEvents(ctx, projectID, nil)
+		events, page, err := bugsnag.ListPage[*bugsnagAPI.Event](ctx, cfg.Client(ctx), "projects/"+url.PathEscape(projectID)+"/events", bugsnag.EncodeFilters(filters), pageOptions(req))
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve events: %v", err)), nil
 		}
+		if format != formatJSON {
+			return mcp.NewToolResultText(formatEventList(events, format, page.NextCursor, page.TotalCount)), nil
+		}
 
-		eventsJSON, err := json.MarshalIndent(events, "", "  ")
+		eventsJSON, err := json.MarshalIndent(listResult[*bugsnagAPI.Event]{Items: events, PageInfo: *page}, "", "  ")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal events: %v", err)), nil
 		}
@@ -165,23 +231,18 @@ func HandleGetProjectEventsTool(cfg *config.Config) server.ToolHandlerFunc {
 	}
 }
 
-// getEventIDFromIDOrLink extracts the event ID from either a direct ID or a Bugsnag dashboard link.
-// If a link is provided, it parses the URL and returns the event_id query parameter.
+// getEventIDFromIDOrLink extracts the event ID from either a direct ID or a Bugsnag dashboard link,
+// where it is the event_id query parameter.
 func getEventIDFromIDOrLink(idOrLink string) (string, error) {
-	// If it's a plain ID (no slashes, no http), just return it
-	if !strings.Contains(idOrLink, "/") && !strings.HasPrefix(idOrLink, "http") {
-		return idOrLink, nil
-	}
-
-	// Otherwise, try to parse as URL and extract event_id param
-	u, err := url.Parse(idOrLink)
+	link, err := bugsnag.ParseIDOrLink(idOrLink)
 	if err != nil {
-		return "", fmt.Errorf("invalid event link: %w", err)
+		return "", err
+	}
+	if link == nil {
+		return idOrLink, nil
 	}
-	q := u.Query()
-	eventID := q.Get("event_id")
-	if eventID == "" {
+	if link.EventID == "" {
 		return "", fmt.Errorf("event_id not found in link: %s", idOrLink)
 	}
-	return eventID, nil
+	return link.EventID, nil
 }
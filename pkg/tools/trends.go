@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bugsnagAPI "github.com/sazap10/bugsnag-api-go"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+	"github.com/sazap10/bugsnag-mcp/pkg/config"
+)
+
+const (
+	// defaultTrendWindow is the time window used when no 'since' is given.
+	defaultTrendWindow = "7d"
+	// defaultTrendBuckets is the number of buckets used when no resolution is given.
+	defaultTrendBuckets = 28
+)
+
+// sparkTicks are the characters used to draw a sparkline, from lowest to highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// trendPeak is the bucket with the most events.
+type trendPeak struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Events int       `json:"events"`
+}
+
+// trendStats summarises a trend.
+type trendStats struct {
+	Total int       `json:"total"`
+	Peak  trendPeak `json:"peak"`
+	// Baseline is the median number of events per bucket, excluding the latest bucket.
+	Baseline float64 `json:"baseline"`
+	Latest   int     `json:"latest"`
+	// ChangePercent is the change of the latest bucket relative to the baseline, unset when the baseline is zero.
+	ChangePercent *float64 `json:"change_percent,omitempty"`
+	Sparkline     string   `json:"sparkline"`
+}
+
+// trendResult is the result of a trend tool.
+type trendResult struct {
+	Summary string                `json:"summary"`
+	Stats   trendStats            `json:"stats"`
+	Buckets []bugsnag.TrendBucket `json:"buckets"`
+}
+
+// withTrendOptions returns the tool options shared by the trend tools.
+func withTrendOptions() []mcp.ToolOption {
+	opts := []mcp.ToolOption{
+		mcp.WithString(
+			"resolution",
+			mcp.Description("The time span of each bucket, a number of minutes, hours, days or weeks, e.g. 30m, 1h, 6h or 1d. Takes precedence over 'buckets'"),
+		),
+		mcp.WithNumber(
+			"buckets",
+			mcp.Description(fmt.Sprintf("The number of buckets to split the window into (default %d)", defaultTrendBuckets)),
+			mcp.Min(1),
+		),
+	}
+	return append(opts, withEventFilters()...)
+}
+
+// NewGetErrorTrendTool returns the MCP tool for retrieving the occurrence trend of an error.
+func NewGetErrorTrendTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Retrieves the number of occurrences of an error over time as buckets, with a sparkline and summary (peak, baseline, % change). " +
+			"Use it to answer questions like 'did this spike after yesterday's deploy?'. The window defaults to the last " + defaultTrendWindow),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project the error belongs to"),
+		),
+		mcp.WithString(
+			"error_id",
+			mcp.Required(),
+			mcp.Description("The ID/url of the error to retrieve the trend for"),
+		),
+	}
+	opts = append(opts, withTrendOptions()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(GetErrorTrendToolID, opts...)
+}
+
+// HandleGetErrorTrendTool handles the tool call to retrieve the occurrence trend of an error.
+func HandleGetErrorTrendTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+		reqParam, err := req.RequireString("error_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'error_id': %v", err)), nil
+		}
+		errorID, err := bugsnag.ErrorIDFromIDOrLink(reqParam)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid error ID or link: %v", err)), nil
+		}
+
+		path := "projects/" + url.PathEscape(projectID) + "/errors/" + url.PathEscape(errorID) + "/trend"
+		return handleTrend(ctx, cfg, req, path)
+	}
+}
+
+// NewGetProjectTrendTool returns the MCP tool for retrieving the event trend of a project.
+func NewGetProjectTrendTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Retrieves the number of events in a project over time as buckets, with a sparkline and summary (peak, baseline, % change). " +
+			"The window defaults to the last " + defaultTrendWindow),
+		mcp.WithString(
+			"project_id",
+			mcp.Required(),
+			mcp.Description("The ID, slug, name or dashboard URL of the project to retrieve the trend for"),
+		),
+	}
+	opts = append(opts, withTrendOptions()...)
+	opts = append(opts, withCacheBypass())
+	return mcp.NewTool(GetProjectTrendToolID, opts...)
+}
+
+// HandleGetProjectTrendTool handles the tool call to retrieve the event trend of a project.
+func HandleGetProjectTrendTool(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, req)
+		projectID, err := req.RequireString("project_id")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("missing required parameter 'project_id': %v", err)), nil
+		}
+		projectID, err = cfg.Resolver(ctx).ResolveProject(ctx, projectID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve project: %v", err)), nil
+		}
+
+		path := "projects/" + url.PathEscape(projectID) + "/trend"
+		return handleTrend(ctx, cfg, req, path)
+	}
+}
+
+// handleTrend retrieves the trend buckets from the given trend endpoint and summarises them.
+func handleTrend(ctx context.Context, cfg *config.Config, req mcp.CallToolRequest, path string) (*mcp.CallToolResult, error) {
+	filters, err := eventFilters(req, timeNow())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if req.GetString("since", "") == "" {
+		since, _ := bugsnag.ParseTime(defaultTrendWindow, timeNow())
+		filters = append(filters, bugsnagAPI.Filter{
+			Key:   "event.since",
+			Type:  bugsnag.FilterTypeEqual,
+			Value: since.UTC().Format(time.RFC3339),
+		})
+	}
+
+	query := bugsnag.EncodeFilters(filters)
+	if resolution := req.GetString("resolution", ""); resolution != "" {
+		d, err := bugsnag.ParseDuration(resolution)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid 'resolution': %v", err)), nil
+		}
+		if d <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid 'resolution' %q: must be longer than zero", resolution)), nil
+		}
+		query.Set("resolution", resolution)
+	} else {
+		query.Set("buckets_count", strconv.Itoa(req.GetInt("buckets", defaultTrendBuckets)))
+	}
+
+	var buckets []bugsnag.TrendBucket
+	if _, err := bugsnag.List(ctx, cfg.Client(ctx), path, query, &buckets); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to retrieve trend: %v", err)), nil
+	}
+
+	stats := summarizeTrend(buckets)
+	trendJSON, err := json.MarshalIndent(trendResult{
+		Summary: formatTrendSummary(stats, buckets),
+		Stats:   stats,
+		Buckets: buckets,
+	}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal trend: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(trendJSON)), nil
+}
+
+// summarizeTrend computes the total, peak, baseline and change of the latest bucket for a trend.
+func summarizeTrend(buckets []bugsnag.TrendBucket) trendStats {
+	var stats trendStats
+	if len(buckets) == 0 {
+		return stats
+	}
+
+	counts := make([]int, len(buckets))
+	for i, b := range buckets {
+		counts[i] = b.EventsCount
+		stats.Total += b.EventsCount
+		if i == 0 || b.EventsCount > stats.Peak.Events {
+			stats.Peak = trendPeak{From: b.From, To: b.To, Events: b.EventsCount}
+		}
+	}
+	stats.Latest = counts[len(counts)-1]
+	stats.Sparkline = sparkline(counts)
+
+	previous := counts[:len(counts)-1]
+	if len(previous) == 0 {
+		return stats
+	}
+	stats.Baseline = median(previous)
+	if stats.Baseline > 0 {
+		change := math.Round((float64(stats.Latest)-stats.Baseline)/stats.Baseline*1000) / 10
+		stats.ChangePercent = &change
+	}
+	return stats
+}
+
+// formatTrendSummary renders a one line, human readable summary of a trend.
+func formatTrendSummary(stats trendStats, buckets []bugsnag.TrendBucket) string {
+	if len(buckets) == 0 {
+		return "No trend data for the requested window."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s → %s (%d buckets): %d events, peak %d at %s, baseline %s, latest %d",
+		stats.Sparkline,
+		buckets[0].From.UTC().Format(time.RFC3339),
+		buckets[len(buckets)-1].To.UTC().Format(time.RFC3339),
+		len(buckets),
+		stats.Total,
+		stats.Peak.Events,
+		stats.Peak.From.UTC().Format(time.RFC3339),
+		strconv.FormatFloat(stats.Baseline, 'f', -1, 64),
+		stats.Latest,
+	)
+	if stats.ChangePercent != nil {
+		fmt.Fprintf(&b, " (%+.1f%% vs baseline)", *stats.ChangePercent)
+	}
+	return b.String()
+}
+
+// sparkline draws the counts as a sparkline scaled to the largest count.
+func sparkline(counts []int) string {
+	maxCount := slices.Max(counts)
+	var b strings.Builder
+	for _, c := range counts {
+		idx := 0
+		if maxCount > 0 {
+			idx = c * (len(sparkTicks) - 1) / maxCount
+		}
+		b.WriteRune(sparkTicks[idx])
+	}
+	return b.String()
+}
+
+// median returns the median of the counts.
+func median(counts []int) float64 {
+	sorted := slices.Clone(counts)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return float64(sorted[mid])
+	}
+	return float64(sorted[mid-1]+sorted[mid]) / 2
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sazap10/bugsnag-mcp/pkg/bugsnag"
+)
+
+// hourlyBuckets builds consecutive one hour trend buckets with the given counts.
+func hourlyBuckets(start time.Time, counts ...int) []bugsnag.TrendBucket {
+	buckets := make([]bugsnag.TrendBucket, len(counts))
+	for i, c := range counts {
+		from := start.Add(time.Duration(i) * time.Hour)
+		buckets[i] = bugsnag.TrendBucket{From: from, To: from.Add(time.Hour), EventsCount: c}
+	}
+	return buckets
+}
+
+func TestSummarizeTrend(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		counts     []int
+		wantTotal  int
+		wantPeak   int
+		wantPeakAt time.Time
+		wantBase   float64
+		wantLatest int
+		wantChange *float64
+		wantSpark  string
+	}{
+		{
+			name:       "spike in latest bucket",
+			counts:     []int{2, 4, 3, 4, 14},
+			wantTotal:  27,
+			wantPeak:   14,
+			wantPeakAt: start.Add(4 * time.Hour),
+			wantBase:   3.5,
+			wantLatest: 14,
+			wantChange: ptr(300.0),
+			wantSpark:  "▂▃▂▃█",
+		},
+		{
+			name:       "odd number of previous buckets",
+			counts:     []int{10, 0, 5, 5},
+			wantTotal:  20,
+			wantPeak:   10,
+			wantPeakAt: start,
+			wantBase:   5,
+			wantLatest: 5,
+			wantChange: ptr(0.0),
+			wantSpark:  "█▁▄▄",
+		},
+		{
+			name:       "zero baseline has no change",
+			counts:     []int{0, 0, 0, 7},
+			wantTotal:  7,
+			wantPeak:   7,
+			wantPeakAt: start.Add(3 * time.Hour),
+			wantLatest: 7,
+			wantSpark:  "▁▁▁█",
+		},
+		{
+			name:       "single bucket",
+			counts:     []int{3},
+			wantTotal:  3,
+			wantPeak:   3,
+			wantPeakAt: start,
+			wantLatest: 3,
+			wantSpark:  "█",
+		},
+		{
+			name:      "no events",
+			counts:    []int{0, 0},
+			wantSpark: "▁▁",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeTrend(hourlyBuckets(start, tt.counts...))
+			if got.Total != tt.wantTotal || got.Peak.Events != tt.wantPeak || got.Latest != tt.wantLatest {
+				t.Errorf("summarizeTrend() total/peak/latest = %d/%d/%d, want %d/%d/%d", got.Total, got.Peak.Events, got.Latest, tt.wantTotal, tt.wantPeak, tt.wantLatest)
+			}
+			if tt.wantPeak > 0 && !got.Peak.From.Equal(tt.wantPeakAt) {
+				t.Errorf("summarizeTrend() peak at %v, want %v", got.Peak.From, tt.wantPeakAt)
+			}
+			if got.Baseline != tt.wantBase {
+				t.Errorf("summarizeTrend() baseline = %v, want %v", got.Baseline, tt.wantBase)
+			}
+			if (got.ChangePercent == nil) != (tt.wantChange == nil) || (got.ChangePercent != nil && *got.ChangePercent != *tt.wantChange) {
+				t.Errorf("summarizeTrend() change = %v, want %v", got.ChangePercent, tt.wantChange)
+			}
+			if got.Sparkline != tt.wantSpark {
+				t.Errorf("summarizeTrend() sparkline = %q, want %q", got.Sparkline, tt.wantSpark)
+			}
+		})
+	}
+
+	if got := summarizeTrend(nil); got.Total != 0 || got.Sparkline != "" {
+		t.Errorf("summarizeTrend(nil) = %+v, want zero value", got)
+	}
+}
+
+func TestFormatTrendSummary(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	buckets := hourlyBuckets(start, 2, 4, 3, 4, 14)
+
+	want := "▂▃▂▃█ 2025-06-01T00:00:00Z → 2025-06-01T05:00:00Z (5 buckets): 27 events, peak 14 at 2025-06-01T04:00:00Z, baseline 3.5, latest 14 (+300.0% vs baseline)"
+	if got := formatTrendSummary(summarizeTrend(buckets), buckets); got != want {
+		t.Errorf("formatTrendSummary() = %q, want %q", got, want)
+	}
+
+	if got := formatTrendSummary(summarizeTrend(nil), nil); got != "No trend data for the requested window." {
+		t.Errorf("formatTrendSummary() = %q for no buckets", got)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}